@@ -0,0 +1,110 @@
+package main
+
+import (
+	"dslx/internal/hogwarts"
+	"dslx/internal/stats"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+func main() {
+	featureList := flag.String("features", "", "comma-separated features to check (default: all courses)")
+	correlationThreshold := flag.Float64("correlation-threshold", 0.9, "absolute correlation above which a pair is flagged")
+	vifThreshold := flag.Float64("vif-threshold", 10, "variance inflation factor above which a feature is flagged")
+	conditionThreshold := flag.Float64("condition-threshold", 30, "condition number above which the design matrix is flagged")
+	flag.Usage = func() {
+		fmt.Println("Usage: collinearity [--features <a,b,...>] [--vif-threshold <vif>] <csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	csvFilePath := flag.Arg(0)
+
+	var features []string
+	if *featureList != "" {
+		for _, feature := range strings.Split(*featureList, ",") {
+			features = append(features, strings.TrimSpace(feature))
+		}
+	}
+
+	dataset, err := hogwarts.LoadDatasetWithFeatures(csvFilePath, true, features)
+	if err != nil {
+		fmt.Println("Error loading dataset:", err)
+		os.Exit(1)
+	}
+
+	featureColumnWidth := len("Feature")
+	for _, featureName := range dataset.FeatureNames {
+		featureColumnWidth = max(featureColumnWidth, len(featureName))
+	}
+
+	// The condition number is taken on the design matrix the model trains on:
+	// standardized features, with missing values at the mean, and a bias
+	// column.
+	design := make([][]float64, len(dataset.Features))
+	for i, row := range dataset.Features {
+		design[i] = make([]float64, 0, len(row)+1)
+		design[i] = append(design[i], 1.0)
+		for j, value := range row {
+			if math.IsNaN(value) {
+				value = dataset.Means[j]
+			}
+			design[i] = append(design[i], (value-dataset.Means[j])/dataset.Stds[j])
+		}
+	}
+
+	conditionNumber, err := stats.ConditionNumber(design)
+	if err != nil {
+		fmt.Println("Error computing condition number:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Condition number: %.2f%s\n", conditionNumber, flagged(conditionNumber > *conditionThreshold))
+
+	correlations, err := stats.CorrelationMatrix(dataset.Features)
+	if err != nil {
+		fmt.Println("Error computing correlations:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\nPairs with |correlation| > %g:\n", *correlationThreshold)
+	pairs := 0
+	for i := range correlations {
+		for j := i + 1; j < len(correlations); j++ {
+			if math.Abs(correlations[i][j]) > *correlationThreshold {
+				fmt.Printf("  %-*s  %-*s  %9.6f\n",
+					featureColumnWidth, dataset.FeatureNames[i], featureColumnWidth, dataset.FeatureNames[j], correlations[i][j])
+				pairs++
+			}
+		}
+	}
+	if pairs == 0 {
+		fmt.Println("  none")
+	}
+
+	fmt.Println()
+	factors, err := stats.VarianceInflationFactors(dataset.Features)
+	if err != nil {
+		fmt.Println("Error computing variance inflation factors:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%-*s %12s\n", featureColumnWidth, "Feature", "VIF")
+	for j, featureName := range dataset.FeatureNames {
+		fmt.Printf("%-*s %12.4f%s\n", featureColumnWidth, featureName, factors[j], flagged(factors[j] > *vifThreshold))
+	}
+}
+
+func flagged(isFlagged bool) string {
+	if isFlagged {
+		return "  <- redundant"
+	}
+	return ""
+}
@@ -2,23 +2,91 @@ package main
 
 import (
 	"dslx/internal/hogwarts"
+	"dslx/internal/stats"
+	"flag"
 	"fmt"
 	"os"
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: describe <csv_file_path>")
+	percentileMethodName := flag.String("percentile-method", "linear",
+		"quantile interpolation: linear, lower, higher, nearest, midpoint, hazen, weibull or median_unbiased")
+	weightColumn := flag.String("weight-column", "", "column holding per-row sample weights")
+	ddof := flag.Int("ddof", 0, "delta degrees of freedom for the standard deviation (0 population, 1 sample)")
+	confidence := flag.Float64("ci", 0, "confidence level in percent for bootstrap intervals, e.g. 95 (0 disables)")
+	intervalMethodName := flag.String("ci-method", "percentile", "bootstrap interval method: percentile or bca")
+	resamples := flag.Int("resamples", 2000, "number of bootstrap resamples")
+	seed := flag.Int64("seed", 1, "seed for bootstrap resampling")
+	stratify := flag.Bool("stratify", false, "resample within each house")
+	normality := flag.Bool("normality", false,
+		"print Shapiro-Wilk, Anderson-Darling, Kolmogorov-Smirnov and D'Agostino K2 tests per feature and house")
+	flag.Usage = func() {
+		fmt.Println("Usage: describe [--percentile-method <method>] [--weight-column <name>] [--ddof <n>] [--ci <level>] [--normality] <csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
 		os.Exit(1)
 	}
 
-	csvFilePath := os.Args[1]
+	csvFilePath := flag.Arg(0)
+
+	if *ddof < 0 {
+		fmt.Println("Error: --ddof must not be negative")
+		os.Exit(1)
+	}
 
-	dataset, err := hogwarts.LoadDataset(csvFilePath, true)
+	percentileMethod, err := stats.ParsePercentileMethod(*percentileMethodName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	intervalMethod, err := stats.ParseIntervalMethod(*intervalMethodName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	dataset, err := hogwarts.LoadDatasetWithOptions(csvFilePath, hogwarts.LoadOptions{
+		SkipEmptyHouses:  true,
+		WeightColumn:     *weightColumn,
+		PercentileMethod: percentileMethod,
+		Ddof:             *ddof,
+	})
 	if err != nil {
 		fmt.Println("Error loading dataset:", err)
 		os.Exit(1)
 	}
 
-	fmt.Println(dataset)
+	if *normality {
+		description, err := dataset.DescribeNormality()
+		if err != nil {
+			fmt.Println("Error computing normality tests:", err)
+			os.Exit(1)
+		}
+		fmt.Println(description)
+		return
+	}
+
+	if *confidence == 0 {
+		fmt.Println(dataset)
+		return
+	}
+
+	description, err := dataset.DescribeWithIntervals(hogwarts.IntervalOptions{
+		Level:            *confidence / 100,
+		Method:           intervalMethod,
+		Resamples:        *resamples,
+		Seed:             *seed,
+		StratifyByHouses: *stratify,
+	})
+	if err != nil {
+		fmt.Println("Error computing confidence intervals:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(description)
 }
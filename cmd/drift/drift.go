@@ -0,0 +1,127 @@
+package main
+
+import (
+	"dslx/internal/hogwarts"
+	"dslx/internal/stats"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+func main() {
+	featureList := flag.String("features", "", "comma-separated features to compare (default: all courses)")
+	bins := flag.Int("bins", 10, "number of reference quantile bins for the population stability index")
+	psiThreshold := flag.Float64("psi-threshold", 0.2, "population stability index above which a feature is flagged")
+	ksThreshold := flag.Float64("ks-threshold", 0.1, "Kolmogorov-Smirnov statistic above which a feature is flagged")
+	wassersteinThreshold := flag.Float64("wasserstein-threshold", 0.1,
+		"Wasserstein distance, in reference standard deviations, above which a feature is flagged")
+	missingThreshold := flag.Float64("missing-threshold", 0.05, "absolute change in missing rate above which a feature is flagged")
+	flag.Usage = func() {
+		fmt.Println("Usage: drift [--features <a,b,...>] [--psi-threshold <psi>] <reference_csv_file_path> <current_csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var features []string
+	if *featureList != "" {
+		for _, feature := range strings.Split(*featureList, ",") {
+			features = append(features, strings.TrimSpace(feature))
+		}
+	}
+
+	// Houses are not compared, so rows of an unlabelled cohort are kept.
+	reference, err := hogwarts.LoadDatasetWithFeatures(flag.Arg(0), false, features)
+	if err != nil {
+		fmt.Println("Error loading reference dataset:", err)
+		os.Exit(1)
+	}
+
+	current, err := hogwarts.LoadDatasetWithFeatures(flag.Arg(1), false, reference.FeatureNames)
+	if err != nil {
+		fmt.Println("Error loading current dataset:", err)
+		os.Exit(1)
+	}
+
+	featureColumnWidth := len("Feature")
+	for _, featureName := range reference.FeatureNames {
+		featureColumnWidth = max(featureColumnWidth, len(featureName))
+	}
+
+	fmt.Printf("%-*s %10s %10s %10s %12s %12s %12s\n", featureColumnWidth, "Feature",
+		"PSI", "KS", "KS p", "Wasserstein", "Missing ref", "Missing cur")
+
+	drifted := 0
+	for i, featureName := range reference.FeatureNames {
+		referenceValues := reference.GetFeatureValues(i)
+		currentValues := current.GetFeatureValues(i)
+
+		// A feature without values in either dataset has no distribution to
+		// compare, so its metrics are NaN and only the missing rate flags it.
+		psi, err := stats.PopulationStabilityIndex(referenceValues, currentValues, *bins)
+		if errors.Is(err, stats.ErrNoData) {
+			psi = math.NaN()
+		} else if err != nil {
+			fmt.Printf("Error computing population stability index for %s: %v\n", featureName, err)
+			os.Exit(1)
+		}
+
+		ks, err := stats.TwoSampleKolmogorovSmirnov(referenceValues, currentValues)
+		if errors.Is(err, stats.ErrNoData) {
+			ks = stats.TestResult{Statistic: math.NaN(), PValue: math.NaN()}
+		} else if err != nil {
+			fmt.Printf("Error computing Kolmogorov-Smirnov statistic for %s: %v\n", featureName, err)
+			os.Exit(1)
+		}
+
+		wasserstein, err := stats.WassersteinDistance(referenceValues, currentValues)
+		if errors.Is(err, stats.ErrNoData) {
+			wasserstein = math.NaN()
+		} else if err != nil {
+			fmt.Printf("Error computing Wasserstein distance for %s: %v\n", featureName, err)
+			os.Exit(1)
+		}
+		// A reference without spread gives the distance no scale, so it is
+		// NaN and never flags the feature.
+		if std := stats.Std(stats.RemoveMissingValues(referenceValues)); std > 0 {
+			wasserstein /= std
+		} else {
+			wasserstein = math.NaN()
+		}
+
+		referenceMissing := stats.MissingRate(referenceValues)
+		currentMissing := stats.MissingRate(currentValues)
+
+		reasons := make([]string, 0)
+		if psi > *psiThreshold {
+			reasons = append(reasons, "psi")
+		}
+		if ks.Statistic > *ksThreshold {
+			reasons = append(reasons, "ks")
+		}
+		if wasserstein > *wassersteinThreshold {
+			reasons = append(reasons, "wasserstein")
+		}
+		if math.Abs(currentMissing-referenceMissing) > *missingThreshold {
+			reasons = append(reasons, "missing")
+		}
+
+		marker := ""
+		if len(reasons) > 0 {
+			marker = "  <- drift (" + strings.Join(reasons, ", ") + ")"
+			drifted++
+		}
+
+		fmt.Printf("%-*s %10.4f %10.4f %10.4f %12.4f %12.4f %12.4f%s\n", featureColumnWidth, featureName,
+			psi, ks.Statistic, ks.PValue, wasserstein, referenceMissing, currentMissing, marker)
+	}
+
+	fmt.Printf("\n%d of %d features drifted\n", drifted, len(reference.FeatureNames))
+}
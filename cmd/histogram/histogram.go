@@ -2,32 +2,49 @@ package main
 
 import (
 	"dslx/internal/hogwarts"
+	"dslx/internal/plotting"
 	"dslx/internal/stats"
+	"flag"
 	"fmt"
 	"image/color"
 	"os"
 
 	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 	"gonum.org/v1/plot/vg/vgimg"
 )
 
-var houseColors = map[string]color.RGBA{
-	"Gryffindor": {R: 116, G: 0, B: 1, A: 248},
-	"Hufflepuff": {R: 97, G: 75, B: 58, A: 248},
-	"Ravenclaw":  {R: 14, G: 26, B: 64, A: 248},
-	"Slytherin":  {R: 26, G: 71, B: 42, A: 248},
-}
-
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: histogram <csv_file_path>")
+	densityModeName := flag.String("density", "none", "per-house density curves: none, overlay or replace")
+	kernelName := flag.String("kernel", "gaussian",
+		"density kernel: gaussian, epanechnikov, uniform, triangular, biweight or cosine")
+	bandwidthName := flag.String("bandwidth", "silverman", "density bandwidth selector: silverman, scott or lscv")
+	binningSpec := flag.String("bins", "fd", "binning rule (sturges, scott, fd, sqrt), bin count (20) or bin width (width=0.5)")
+	flag.Usage = func() {
+		fmt.Println("Usage: histogram [--bins <rule>] [--density <mode>] [--kernel <kernel>] [--bandwidth <method>] <csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	csvFilePath := flag.Arg(0)
+
+	densityOptions, err := plotting.ParseDensityOptions(*densityModeName, *kernelName, *bandwidthName)
+	if err != nil {
+		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
 
-	csvFilePath := os.Args[1]
+	binning, err := stats.ParseBinning(*binningSpec)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
 
 	dataset, err := hogwarts.LoadDataset(csvFilePath, true)
 	if err != nil {
@@ -50,23 +67,53 @@ func main() {
 		p.Title.Text = featureName
 		p.X.Label.Text = "Score"
 		p.Y.Label.Text = "Frequency"
+		if densityOptions.Mode == plotting.DensityReplace {
+			p.Y.Label.Text = "Density"
+		}
+
+		featureValues := stats.RemoveMissingValues(dataset.GetFeatureValues(i))
+		if len(featureValues) == 0 {
+			plots[row][col] = p
+			continue
+		}
+		featureMin := stats.Min(featureValues)
+		featureMax := stats.Max(featureValues)
+		edges, err := stats.BinEdges(featureValues, binning)
+		if err != nil {
+			fmt.Printf("Error binning %s: %v\n", featureName, err)
+			os.Exit(1)
+		}
 
 		for _, house := range dataset.Houses {
 			values := stats.RemoveMissingValues(dataset.GetFeatureValuesByHouse(i, house))
 
 			if len(values) > 0 {
-				h, err := plotter.NewHist(plotter.Values(values), 20)
-				if err != nil {
-					fmt.Printf("Error creating histogram for %s - %s: %v\n", featureName, house, err)
-					continue
-				}
+				densityScale := 1.0
 
-				h.FillColor = houseColors[house]
-				h.LineStyle.Width = vg.Points(0.5)
-				h.LineStyle.Color = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+				if densityOptions.Mode != plotting.DensityReplace {
+					h := plotting.NewHistogram(values, edges)
+					h.FillColor = plotting.HouseColors[house]
+					h.LineStyle.Width = vg.Points(0.5)
+					h.LineStyle.Color = color.RGBA{R: 0, G: 0, B: 0, A: 255}
 
-				p.Add(h)
-				p.Legend.Add(house, h)
+					p.Add(h)
+					p.Legend.Add(house, h)
+					densityScale = float64(len(values)) * h.Width
+				}
+
+				if densityOptions.Mode != plotting.DensityNone {
+					line, err := plotting.DensityLine(values, featureMin, featureMax, densityScale, densityOptions)
+					if err != nil {
+						fmt.Printf("Error estimating density for %s - %s: %v\n", featureName, house, err)
+						continue
+					}
+
+					line.LineStyle.Color = plotting.HouseColors[house]
+					p.Add(line)
+					if densityOptions.Mode == plotting.DensityReplace {
+						p.Legend.Add(house, line)
+					}
+				}
 			}
 		}
 
@@ -3,40 +3,76 @@ package main
 import (
 	"dslx/internal/hogwarts"
 	"dslx/internal/logisticregression"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
 )
 
 func main() {
-	if len(os.Args) != 3 {
-		fmt.Println("Usage: logreg_predict <csv_file_path> <models_file_path>")
+	withProbabilities := flag.Bool("probabilities", false, "add one probability column per house to the output")
+	topK := flag.Int("top-k", 0, "add the k most probable houses and their probabilities to the output")
+	minProbability := flag.Float64("min-probability", 0, "reject predictions whose house probability is below this")
+	minMargin := flag.Float64("min-margin", 0, "reject predictions that lead the runner-up house by less than this")
+	unknownLabel := flag.String("unknown-label", logisticregression.DefaultUnknownLabel, "house written for rejected rows")
+	flag.Usage = func() {
+		fmt.Println("Usage: logreg_predict [--probabilities] [--top-k <k>] [--min-probability <p>] [--min-margin <m>] <csv_file_path> <models_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *topK < 0 {
+		fmt.Println("Error parsing top-k: must not be negative")
 		os.Exit(1)
 	}
 
-	csvFilePath := os.Args[1]
-	modelsFilePath := os.Args[2]
+	csvFilePath := flag.Arg(0)
+	modelsFilePath := flag.Arg(1)
 
-	dataset, err := hogwarts.LoadDatasetWithFeatures(csvFilePath, false, []string{
-		"Astronomy",
-		"Herbology",
-		"Defense Against the Dark Arts",
-		"Divination",
-		"Muggle Studies",
-		"Ancient Runes",
-		"Charms",
-	})
+	model, err := logisticregression.LoadModelFromFile(modelsFilePath)
+	if err != nil {
+		fmt.Println("Error loading model:", err)
+		os.Exit(1)
+	}
+
+	dataset, err := hogwarts.LoadDatasetWithFeatures(csvFilePath, false, model.FeatureNames)
 	if err != nil {
 		fmt.Println("Error loading dataset:", err)
 		os.Exit(1)
 	}
 
-	model, err := logisticregression.LoadModelFromFile(modelsFilePath)
+	rejectOptions := logisticregression.RejectOptions{
+		MinProbability: *minProbability,
+		MinMargin:      *minMargin,
+		UnknownLabel:   *unknownLabel,
+	}
+	withReasons := rejectOptions.MinProbability > 0 || rejectOptions.MinMargin > 0
+
+	decisions, err := model.PredictWithRejection(dataset, rejectOptions)
 	if err != nil {
-		fmt.Println("Error loading model:", err)
+		fmt.Println("Error predicting houses:", err)
 		os.Exit(1)
 	}
 
-	predictions := model.Predict(dataset)
+	var probabilities *mat.Dense
+	if *withProbabilities {
+		probabilities = model.PredictProba(dataset)
+	}
+
+	var ranked [][]logisticregression.Prediction
+	if *topK > 0 {
+		ranked, err = model.PredictTopK(dataset, *topK)
+		if err != nil {
+			fmt.Println("Error ranking houses:", err)
+			os.Exit(1)
+		}
+	}
 
 	outputFile, err := os.Create("houses.csv")
 	if err != nil {
@@ -45,8 +81,59 @@ func main() {
 	}
 	defer outputFile.Close()
 
-	fmt.Fprintln(outputFile, "Index,Hogwarts House")
-	for i, prediction := range predictions {
-		fmt.Fprintf(outputFile, "%d,%s\n", i, prediction)
+	header := []string{"Index", "Hogwarts House"}
+	if withReasons {
+		header = append(header, "Reason")
+	}
+	if *withProbabilities {
+		for _, label := range model.LabelNames {
+			header = append(header, "P("+label+")")
+		}
+	}
+	if *topK > 0 {
+		for k := 1; k <= min(*topK, len(model.LabelNames)); k++ {
+			header = append(header, fmt.Sprintf("House %d", k), fmt.Sprintf("Probability %d", k))
+		}
+	}
+	fmt.Fprintln(outputFile, strings.Join(header, ","))
+
+	rejected := map[logisticregression.RejectReason]int{}
+	for i, decision := range decisions {
+		fields := []string{fmt.Sprint(i), decision.Label}
+		if withReasons {
+			fields = append(fields, string(decision.Reason))
+		}
+		if decision.Rejected() {
+			rejected[decision.Reason]++
+		}
+		if *withProbabilities {
+			for j := range model.LabelNames {
+				fields = append(fields, fmt.Sprintf("%.6f", probabilities.At(i, j)))
+			}
+		}
+		if ranked != nil {
+			for _, candidate := range ranked[i] {
+				fields = append(fields, candidate.Label, fmt.Sprintf("%.6f", candidate.Probability))
+			}
+		}
+		fmt.Fprintln(outputFile, strings.Join(fields, ","))
+	}
+
+	total := 0
+	var reasons []string
+	for _, reason := range []logisticregression.RejectReason{
+		logisticregression.RejectedNoProbability,
+		logisticregression.RejectedLowProbability,
+		logisticregression.RejectedLowMargin,
+	} {
+		if rejected[reason] > 0 {
+			total += rejected[reason]
+			reasons = append(reasons, fmt.Sprintf("%d %s", rejected[reason], reason))
+		}
+	}
+	if total == 0 {
+		fmt.Printf("Rejected 0 of %d rows\n", len(decisions))
+	} else {
+		fmt.Printf("Rejected %d of %d rows (%s)\n", total, len(decisions), strings.Join(reasons, ", "))
 	}
 }
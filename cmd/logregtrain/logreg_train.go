@@ -1,36 +1,234 @@
 package main
 
 import (
+	"dslx/internal/featureselection"
 	"dslx/internal/hogwarts"
 	"dslx/internal/logisticregression"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: logreg_train <csv_file_path>")
+	strategyName := flag.String("strategy", "one-vs-rest", "multi-class strategy: one-vs-rest or softmax")
+	weightColumn := flag.String("weight-column", "", "column holding per-row sample weights")
+	selectionName := flag.String("selection", "none", "feature selection: none, forward, backward or rfe")
+	folds := flag.Int("folds", 5, "cross-validation folds used to score feature sets")
+	maxFeatures := flag.Int("max-features", 0, "largest feature set to select (0 for no limit)")
+	solverName := flag.String("solver", "gradient-descent", "solver: gradient-descent, newton or lbfgs")
+	gradientTolerance := flag.Float64("gradient-tolerance", 1e-6, "gradient norm at which training has converged")
+	regularizationName := flag.String("regularization", "none", "penalty: none, l2, l1 or elasticnet (l1 needs gradient-descent)")
+	lambda := flag.Float64("lambda", 0.01, "regularization strength")
+	l1Ratio := flag.Float64("l1-ratio", 0.5, "share of the l1 penalty for elasticnet")
+	tolerance := flag.Float64("tolerance", 0, "relative cost change below which training stops (0 disables)")
+	validationFraction := flag.Float64("validation-fraction", 0, "share of rows held out for early stopping (0 disables)")
+	patience := flag.Int("patience", 10, "epochs without validation improvement before early stopping")
+	monitorName := flag.String("monitor", "loss", "validation metric for early stopping: loss or accuracy")
+	classWeightSpec := flag.String("class-weight", "none", "class weights: none, balanced or house=weight pairs such as Gryffindor=2,Slytherin=1.5")
+	resampleName := flag.String("resample", "none", "resample the houses of the training rows, after any validation split, to equal size: none, oversample, undersample or smote")
+	smoteNeighbours := flag.Int("smote-neighbours", 5, "nearest neighbours used by smote")
+	optimizerName := flag.String("optimizer", "sgd", "optimizer: sgd, momentum, nesterov, adagrad, rmsprop or adam")
+	learningRate := flag.Float64("learning-rate", 0.01, "step size of the optimizer")
+	momentum := flag.Float64("momentum", 0.9, "velocity decay for momentum and nesterov")
+	decay := flag.Float64("decay", 0.9, "squared gradient decay for rmsprop")
+	beta1 := flag.Float64("beta1", 0.9, "first moment decay for adam")
+	beta2 := flag.Float64("beta2", 0.999, "second moment decay for adam")
+	epsilon := flag.Float64("epsilon", 1e-8, "denominator offset for adagrad, rmsprop and adam")
+	scheduleName := flag.String("schedule", "constant", "learning rate schedule: constant, step, exponential, inverse-time or cosine")
+	decayRate := flag.Float64("decay-rate", 0, "decay of the step, exponential and inverse-time schedules (default 0.5, 0.99 and 0.01)")
+	stepSize := flag.Int("step-size", 100, "epochs between decays of the step schedule")
+	minLearningRate := flag.Float64("min-learning-rate", 0, "final learning rate of the cosine schedule")
+	warmupEpochs := flag.Int("warmup-epochs", 0, "epochs of linear learning rate warm-up before the schedule")
+	workers := flag.Int("workers", 0, "one-vs-rest classifiers trained in parallel (0 for one per CPU)")
+	logEvery := flag.Int("log-every", 100, "epochs between progress lines")
+	epochs := flag.Int("epochs", 1000, "number of passes over the training rows")
+	batchSize := flag.Int("batch-size", 0, "rows per gradient step: 0 for full batch, 1 for stochastic, more for mini-batch")
+	ddof := flag.Int("ddof", 0, "delta degrees of freedom of the standard deviations that scale the features (0 population, 1 sample)")
+	seed := flag.Int64("seed", 1, "seed for the cross-validation split and the shuffling of mini-batches")
+	flag.Usage = func() {
+		fmt.Println("Usage: logreg_train [--strategy <strategy>] [--solver <solver>] [--regularization <penalty>] [--validation-fraction <share>] [--class-weight <weights>] [--resample <method>] [--workers <n>] [--optimizer <optimizer>] [--learning-rate <rate>] [--schedule <schedule>] [--epochs <n>] [--batch-size <n>] [--weight-column <name>] [--ddof <n>] [--selection <method>] <csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
 		os.Exit(1)
 	}
 
-	csvFilePath := os.Args[1]
+	csvFilePath := flag.Arg(0)
 
-	dataset, err := hogwarts.LoadDatasetWithFeatures(csvFilePath, true, []string{
-		"Astronomy",
-		"Herbology",
-		"Defense Against the Dark Arts",
-		"Divination",
-		"Muggle Studies",
-		"Ancient Runes",
-		"Charms",
-	})
+	if *ddof < 0 {
+		fmt.Println("Error: --ddof must not be negative")
+		os.Exit(1)
+	}
+
+	strategy, err := logisticregression.ParseStrategy(*strategyName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	solver, err := logisticregression.ParseSolver(*solverName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	regularizationType, err := logisticregression.ParseRegularizationType(*regularizationName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	scheduleKind, err := logisticregression.ParseScheduleKind(*scheduleName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	classWeights, err := logisticregression.ParseClassWeights(*classWeightSpec)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	resampling, err := hogwarts.ParseResamplingMethod(*resampleName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	monitor, err := logisticregression.ParseMonitor(*monitorName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	optimizerKind, err := logisticregression.ParseOptimizerKind(*optimizerName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	loadOptions := hogwarts.LoadOptions{
+		SkipEmptyHouses: true,
+		WeightColumn:    *weightColumn,
+		Ddof:            *ddof,
+	}
+	if *selectionName == "none" {
+		loadOptions.Features = logisticregression.DefaultFeatureNames
+	}
+
+	dataset, err := hogwarts.LoadDatasetWithOptions(csvFilePath, loadOptions)
 	if err != nil {
 		fmt.Println("Error loading dataset:", err)
 		os.Exit(1)
 	}
 
-	model := logisticregression.TrainNewModel(dataset, 0.01, 1000)
+	// The decay rate default depends on the schedule, so it is only
+	// overridden when given.
+	schedule := logisticregression.DefaultSchedule(scheduleKind)
+	schedule.StepSize = *stepSize
+	schedule.MinLearningRate = *minLearningRate
+	schedule.WarmupEpochs = *warmupEpochs
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "decay-rate" {
+			schedule.DecayRate = *decayRate
+		}
+	})
+
+	trainOptions := logisticregression.TrainOptions{
+		LearningRate: *learningRate,
+		Iterations:   *epochs,
+		BatchSize:    *batchSize,
+		Seed:         *seed,
+		Strategy:     strategy,
+		Solver:       solver,
+		Optimizer: logisticregression.OptimizerConfig{
+			Kind:     optimizerKind,
+			Momentum: *momentum,
+			Decay:    *decay,
+			Beta1:    *beta1,
+			Beta2:    *beta2,
+			Epsilon:  *epsilon,
+		},
+		Schedule:           schedule,
+		GradientTolerance:  *gradientTolerance,
+		Tolerance:          *tolerance,
+		ValidationFraction: *validationFraction,
+		Patience:           *patience,
+		Monitor:            monitor,
+		ClassWeights:       classWeights,
+		Regularization: logisticregression.Regularization{
+			Type:    regularizationType,
+			Lambda:  *lambda,
+			L1Ratio: *l1Ratio,
+		},
+		Resampling:      resampling,
+		SMOTENeighbours: *smoteNeighbours,
+		Workers:         *workers,
+		Log:             os.Stdout,
+		LogEvery:        *logEvery,
+	}
+
+	if *selectionName != "none" {
+		selectionMethod, err := featureselection.ParseSelectionMethod(*selectionName)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Selecting features (%s, %d folds)\n", selectionMethod, *folds)
+		selection, err := featureselection.SelectFeatures(dataset, dataset.FeatureNames, featureselection.SelectionOptions{
+			Method:      selectionMethod,
+			Folds:       *folds,
+			Seed:        *seed,
+			MaxFeatures: *maxFeatures,
+			Training:    trainOptions,
+			Log:         os.Stdout,
+		})
+		if err != nil {
+			fmt.Println("Error selecting features:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Selected %d features (accuracy %.4f): %v\n", len(selection.Features), selection.Accuracy, selection.Features)
+
+		dataset, err = dataset.SelectFeatures(selection.Features)
+		if err != nil {
+			fmt.Println("Error selecting features:", err)
+			os.Exit(1)
+		}
+	}
+
+	model, err := logisticregression.TrainNewModelWithOptions(dataset, trainOptions)
+	if err != nil {
+		fmt.Println("Error training model:", err)
+		os.Exit(1)
+	}
+
+	for _, diagnostics := range model.Diagnostics {
+		if diagnostics.Label != "" {
+			fmt.Printf("%s: %s\n", diagnostics.Label, diagnostics)
+		} else {
+			fmt.Println(diagnostics)
+		}
+	}
+
+	if regularizationType == logisticregression.L1 || regularizationType == logisticregression.ElasticNet {
+		fmt.Println("Features kept by the l1 penalty:")
+		for k, labelName := range model.LabelNames {
+			kept := make([]string, 0)
+			for j, featureName := range model.FeatureNames {
+				// The first weight is the bias.
+				if model.Weights[k][j+1] != 0 {
+					kept = append(kept, featureName)
+				}
+			}
+			fmt.Printf("  %s: %v\n", labelName, kept)
+		}
+	}
 
 	// Saving models to a file
 	modelsJSON, err := json.Marshal(model)
@@ -2,6 +2,10 @@ package main
 
 import (
 	"dslx/internal/hogwarts"
+	"dslx/internal/plotting"
+	"dslx/internal/stats"
+	"errors"
+	"flag"
 	"fmt"
 	"image/color"
 	"math"
@@ -14,20 +18,36 @@ import (
 	"gonum.org/v1/plot/vg/vgimg"
 )
 
-var houseColors = map[string]color.Color{
-	"Gryffindor": color.RGBA{R: 116, G: 0, B: 1, A: 248},
-	"Hufflepuff": color.RGBA{R: 97, G: 75, B: 58, A: 248},
-	"Ravenclaw":  color.RGBA{R: 14, G: 26, B: 64, A: 248},
-	"Slytherin":  color.RGBA{R: 26, G: 71, B: 42, A: 248},
-}
-
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: pairplot <csv_file_path>")
+	densityModeName := flag.String("density", "none", "per-house density curves on the diagonal: none, overlay or replace")
+	kernelName := flag.String("kernel", "gaussian",
+		"density kernel: gaussian, epanechnikov, uniform, triangular, biweight or cosine")
+	bandwidthName := flag.String("bandwidth", "silverman", "density bandwidth selector: silverman, scott or lscv")
+	binningSpec := flag.String("bins", "fd", "binning rule (sturges, scott, fd, sqrt), bin count (20) or bin width (width=0.5)")
+	flag.Usage = func() {
+		fmt.Println("Usage: pairplot [--bins <rule>] [--density <mode>] [--kernel <kernel>] [--bandwidth <method>] <csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	csvFilePath := flag.Arg(0)
+
+	densityOptions, err := plotting.ParseDensityOptions(*densityModeName, *kernelName, *bandwidthName)
+	if err != nil {
+		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
 
-	csvFilePath := os.Args[1]
+	binning, err := stats.ParseBinning(*binningSpec)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
 
 	dataset, err := hogwarts.LoadDataset(csvFilePath, true)
 	if err != nil {
@@ -60,6 +80,15 @@ func main() {
 			if row == col {
 				p.Title.Text = dataset.FeatureNames[row]
 
+				featureValues := stats.RemoveMissingValues(dataset.GetFeatureValues(col))
+				featureMin := stats.Min(featureValues)
+				featureMax := stats.Max(featureValues)
+				edges, err := stats.BinEdges(featureValues, binning)
+				if err != nil && !errors.Is(err, stats.ErrNoData) {
+					fmt.Println("Error binning feature:", err)
+					os.Exit(1)
+				}
+
 				for _, house := range dataset.Houses {
 					values := dataset.GetFeatureValuesByHouse(col, house)
 
@@ -71,18 +100,33 @@ func main() {
 					}
 
 					if len(filteredValues) > 0 {
-						hist, err := plotter.NewHist(filteredValues, 20)
-						if err != nil {
-							fmt.Println("Error creating histogram:", err)
-							os.Exit(1)
+						densityScale := 1.0
+
+						if densityOptions.Mode != plotting.DensityReplace {
+							hist := plotting.NewHistogram(filteredValues, edges)
+							hist.FillColor = plotting.HouseColors[house]
+							hist.LineStyle.Width = vg.Points(0.5)
+							hist.LineStyle.Color = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+							p.Add(hist)
+
+							if row == 0 {
+								p.Legend.Add(house, hist)
+							}
+							densityScale = float64(len(filteredValues)) * hist.Width
 						}
-						hist.FillColor = houseColors[house]
-						hist.LineStyle.Width = vg.Points(0.5)
-						hist.LineStyle.Color = color.RGBA{R: 0, G: 0, B: 0, A: 255}
-						p.Add(hist)
 
-						if row == 0 {
-							p.Legend.Add(house, hist)
+						if densityOptions.Mode != plotting.DensityNone {
+							line, err := plotting.DensityLine(filteredValues, featureMin, featureMax, densityScale, densityOptions)
+							if err != nil {
+								fmt.Printf("Error estimating density for %s - %s: %v\n", dataset.FeatureNames[row], house, err)
+								continue
+							}
+							line.LineStyle.Color = plotting.HouseColors[house]
+							p.Add(line)
+
+							if row == 0 && densityOptions.Mode == plotting.DensityReplace {
+								p.Legend.Add(house, line)
+							}
 						}
 					}
 				}
@@ -109,7 +153,7 @@ func main() {
 							fmt.Println("Error creating scatter:", err)
 							os.Exit(1)
 						}
-						scatter.GlyphStyle.Color = houseColors[house]
+						scatter.GlyphStyle.Color = plotting.HouseColors[house]
 						scatter.GlyphStyle.Radius = vg.Points(1.5)
 						scatter.GlyphStyle.Shape = draw.CircleGlyph{}
 						p.Add(scatter)
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"dslx/internal/decomposition"
+	"dslx/internal/hogwarts"
+	"dslx/internal/plotting"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+func main() {
+	loadings := flag.Int("loadings", 2, "number of leading components whose loadings are printed")
+	standardize := flag.Bool("standardize", true, "scale every feature to unit variance before fitting")
+	flag.Usage = func() {
+		fmt.Println("Usage: pca [--loadings <n>] [--standardize=false] <csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	csvFilePath := flag.Arg(0)
+
+	dataset, err := hogwarts.LoadDataset(csvFilePath, true)
+	if err != nil {
+		fmt.Println("Error loading dataset:", err)
+		os.Exit(1)
+	}
+
+	pca, err := decomposition.FitPCA(dataset, decomposition.PCAOptions{Standardize: *standardize})
+	if err != nil {
+		fmt.Println("Error fitting PCA:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%-10s %18s %12s %12s\n", "Component", "Explained variance", "Ratio", "Cumulative")
+	cumulative := 0.0
+	for k := range pca.Components {
+		cumulative += pca.ExplainedVarianceRatio[k]
+		fmt.Printf("%-10s %18.6f %12.6f %12.6f\n",
+			fmt.Sprintf("PC%d", k+1), pca.ExplainedVariance[k], pca.ExplainedVarianceRatio[k], cumulative)
+	}
+
+	shownLoadings := min(*loadings, len(pca.Components))
+	if shownLoadings > 0 {
+		featureColumnWidth := len("Feature")
+		for _, featureName := range pca.FeatureNames {
+			featureColumnWidth = max(featureColumnWidth, len(featureName))
+		}
+
+		fmt.Println()
+		fmt.Printf("%-*s", featureColumnWidth, "Feature")
+		for k := range shownLoadings {
+			fmt.Printf(" %10s", fmt.Sprintf("PC%d", k+1))
+		}
+		fmt.Println()
+		for j, featureName := range pca.FeatureNames {
+			fmt.Printf("%-*s", featureColumnWidth, featureName)
+			for k := range shownLoadings {
+				fmt.Printf(" %10.6f", pca.Components[k][j])
+			}
+			fmt.Println()
+		}
+	}
+
+	pcaJSON, err := json.Marshal(pca)
+	if err != nil {
+		fmt.Println("Error marshalling PCA:", err)
+		os.Exit(1)
+	}
+
+	err = os.WriteFile("pca.json", pcaJSON, 0644)
+	if err != nil {
+		fmt.Println("Error saving PCA:", err)
+		os.Exit(1)
+	}
+
+	if len(pca.Components) < 2 {
+		fmt.Println("Not enough components for a scatter plot")
+		return
+	}
+
+	projected, err := pca.Transform(dataset)
+	if err != nil {
+		fmt.Println("Error projecting dataset:", err)
+		os.Exit(1)
+	}
+
+	p := plot.New()
+	p.Title.Text = "First two principal components"
+	p.X.Label.Text = fmt.Sprintf("PC1 (%.1f%%)", pca.ExplainedVarianceRatio[0]*100)
+	p.Y.Label.Text = fmt.Sprintf("PC2 (%.1f%%)", pca.ExplainedVarianceRatio[1]*100)
+	p.Add(plotter.NewGrid())
+
+	for _, house := range dataset.Houses {
+		xys := make(plotter.XYs, 0)
+		for i, label := range dataset.Labels {
+			if label == house {
+				xys = append(xys, plotter.XY{X: projected[i][0], Y: projected[i][1]})
+			}
+		}
+
+		scatter, err := plotter.NewScatter(xys)
+		if err != nil {
+			fmt.Println("Error creating scatter:", err)
+			os.Exit(1)
+		}
+		scatter.GlyphStyle.Color = plotting.HouseColors[house]
+		scatter.GlyphStyle.Radius = vg.Points(2)
+		scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+		p.Add(scatter)
+		p.Legend.Add(house, scatter)
+	}
+	p.Legend.Top = true
+
+	if err := p.Save(8*vg.Inch, 8*vg.Inch, "pca.png"); err != nil {
+		fmt.Println("Error writing PNG:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("\nPCA saved to pca.json, projection saved to pca.png")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"dslx/internal/hogwarts"
+	"dslx/internal/plotting"
+	"dslx/internal/stats"
+	"flag"
+	"fmt"
+	"image/color"
+	"os"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+func main() {
+	pooled := flag.Bool("pooled", false, "plot all students together instead of one series per house")
+	flag.Usage = func() {
+		fmt.Println("Usage: qqplot [--pooled] <csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	csvFilePath := flag.Arg(0)
+
+	dataset, err := hogwarts.LoadDataset(csvFilePath, true)
+	if err != nil {
+		fmt.Println("Error loading dataset:", err)
+		os.Exit(1)
+	}
+
+	rows := 4
+	cols := 4
+	plots := make([][]*plot.Plot, rows)
+	for i := range rows {
+		plots[i] = make([]*plot.Plot, cols)
+	}
+
+	for i, featureName := range dataset.FeatureNames {
+		row := i / cols
+		col := i % cols
+
+		p := plot.New()
+		p.Title.Text = featureName
+		p.X.Label.Text = "Theoretical quantile"
+		p.Y.Label.Text = "Standardized score"
+		p.Add(plotter.NewGrid())
+
+		reference := plotter.NewFunction(func(x float64) float64 { return x })
+		reference.XMin = -3.5
+		reference.XMax = 3.5
+		reference.Color = color.RGBA{A: 255}
+		reference.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+		p.Add(reference)
+
+		series := map[string][]float64{"All": dataset.GetFeatureValues(i)}
+		names := []string{"All"}
+		if !*pooled {
+			series = make(map[string][]float64)
+			names = dataset.Houses
+			for _, house := range dataset.Houses {
+				series[house] = dataset.GetFeatureValuesByHouse(i, house)
+			}
+		}
+
+		for _, name := range names {
+			theoretical, sample := stats.NormalQQ(series[name])
+			if len(sample) < 2 {
+				continue
+			}
+
+			// Standardizing puts every house on the same reference line.
+			mean := stats.Mean(sample)
+			std := stats.StdWithDdof(sample, 1)
+			if !(std > 0) {
+				continue
+			}
+
+			xys := make(plotter.XYs, len(sample))
+			for j := range sample {
+				xys[j] = plotter.XY{X: theoretical[j], Y: (sample[j] - mean) / std}
+			}
+
+			scatter, err := plotter.NewScatter(xys)
+			if err != nil {
+				fmt.Printf("Error creating QQ plot for %s - %s: %v\n", featureName, name, err)
+				os.Exit(1)
+			}
+			scatter.GlyphStyle.Color = color.RGBA{R: 80, G: 80, B: 80, A: 255}
+			if houseColor, ok := plotting.HouseColors[name]; ok {
+				scatter.GlyphStyle.Color = houseColor
+			}
+			scatter.GlyphStyle.Radius = vg.Points(1.5)
+			scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+
+			p.Add(scatter)
+			p.Legend.Add(name, scatter)
+		}
+
+		p.Legend.Top = true
+		p.Legend.Left = true
+
+		plots[row][col] = p
+	}
+
+	img := vgimg.New(20*vg.Inch, 20*vg.Inch)
+	dc := draw.New(img)
+
+	t := draw.Tiles{
+		Rows: rows,
+		Cols: cols,
+	}
+
+	canvases := plot.Align(plots, t, dc)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if plots[i][j] != nil {
+				plots[i][j].Draw(canvases[i][j])
+			}
+		}
+	}
+
+	w, err := os.Create("qqplots.png")
+	if err != nil {
+		fmt.Println("Error creating output file:", err)
+		os.Exit(1)
+	}
+	defer w.Close()
+
+	png := vgimg.PngCanvas{Canvas: img}
+	if _, err := png.WriteTo(w); err != nil {
+		fmt.Println("Error writing PNG:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"dslx/internal/featureselection"
+	"dslx/internal/hogwarts"
+	"dslx/internal/stats"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	criterionName := flag.String("sort", "mi", "ranking criterion: mi, anova or chi2")
+	binningSpec := flag.String("bins", "sturges",
+		"discretization for mutual information and chi-square: sturges, scott, fd, sqrt, a count or width=<w>")
+	flag.Usage = func() {
+		fmt.Println("Usage: rankfeatures [--sort <criterion>] [--bins <rule>] <csv_file_path>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	csvFilePath := flag.Arg(0)
+
+	criterion, err := featureselection.ParseCriterion(*criterionName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	binning, err := stats.ParseBinning(*binningSpec)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	dataset, err := hogwarts.LoadDataset(csvFilePath, true)
+	if err != nil {
+		fmt.Println("Error loading dataset:", err)
+		os.Exit(1)
+	}
+
+	scores, err := featureselection.ScoreFeatures(dataset, featureselection.RankingOptions{Binning: binning})
+	if err != nil {
+		fmt.Println("Error scoring features:", err)
+		os.Exit(1)
+	}
+	featureselection.SortScores(scores, criterion)
+
+	featureColumnWidth := len("Feature")
+	for _, score := range scores {
+		if len(score.Feature) > featureColumnWidth {
+			featureColumnWidth = len(score.Feature)
+		}
+	}
+
+	fmt.Printf("%-4s %-*s %12s %14s %12s %14s %12s\n",
+		"Rank", featureColumnWidth, "Feature", "MI (bits)", "ANOVA F", "ANOVA p", "Chi-square", "Chi-square p")
+	for i, score := range scores {
+		fmt.Printf("%-4d %-*s %12.6f %14.4f %12.4g %14.4f %12.4g\n",
+			i+1, featureColumnWidth, score.Feature,
+			score.MutualInformation,
+			score.ANOVA.Statistic, score.ANOVA.PValue,
+			score.ChiSquare.Statistic, score.ChiSquare.PValue)
+	}
+}
@@ -4,7 +4,10 @@ go 1.23.0
 
 toolchain go1.24.9
 
-require gonum.org/v1/plot v0.16.0
+require (
+	gonum.org/v1/gonum v0.16.0
+	gonum.org/v1/plot v0.16.0
+)
 
 require (
 	codeberg.org/go-fonts/liberation v0.5.0 // indirect
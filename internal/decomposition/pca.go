@@ -0,0 +1,179 @@
+package decomposition
+
+import (
+	"dslx/internal/hogwarts"
+	"dslx/internal/stats"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+)
+
+type PCA struct {
+	FeatureNames []string  `json:"feature_names"`
+	Means        []float64 `json:"means"`
+	// Scales divide the centered features. They are all 1 when the fit was not
+	// standardized.
+	Scales []float64 `json:"scales"`
+	// Components holds one unit loading vector per component, over the
+	// features, ordered by decreasing explained variance.
+	Components             [][]float64 `json:"components"`
+	ExplainedVariance      []float64   `json:"explained_variance"`
+	ExplainedVarianceRatio []float64   `json:"explained_variance_ratio"`
+}
+
+type PCAOptions struct {
+	// Components is the number of components to keep. Zero keeps all of them.
+	Components  int
+	Standardize bool
+}
+
+// FitPCA fits the components on the dataset's features. Missing values are
+// replaced by the feature mean, so they do not move the projection.
+func FitPCA(dataset *hogwarts.Dataset, options PCAOptions) (*PCA, error) {
+	if len(dataset.Features) < 2 {
+		return nil, errors.New("PCA needs at least two rows")
+	}
+
+	numFeatures := len(dataset.FeatureNames)
+	means := make([]float64, numFeatures)
+	scales := make([]float64, numFeatures)
+	for j := range numFeatures {
+		means[j] = dataset.Means[j]
+		scales[j] = 1.0
+		if options.Standardize {
+			scales[j] = dataset.Stds[j]
+		}
+		if math.IsNaN(means[j]) || math.IsNaN(scales[j]) {
+			means[j] = 0.0
+			scales[j] = 1.0
+		}
+	}
+
+	pca := &PCA{
+		FeatureNames: dataset.FeatureNames,
+		Means:        means,
+		Scales:       scales,
+	}
+	x := pca.prepare(dataset.Features)
+
+	covariance := make([][]float64, numFeatures)
+	for i := range covariance {
+		covariance[i] = make([]float64, numFeatures)
+	}
+	for _, row := range x {
+		for i := range numFeatures {
+			for j := i; j < numFeatures; j++ {
+				covariance[i][j] += row[i] * row[j]
+			}
+		}
+	}
+	for i := range numFeatures {
+		for j := i; j < numFeatures; j++ {
+			covariance[i][j] /= float64(len(x) - 1)
+			covariance[j][i] = covariance[i][j]
+		}
+	}
+
+	values, vectors, err := stats.SymmetricEigen(covariance)
+	if err != nil {
+		return nil, err
+	}
+
+	totalVariance := 0.0
+	for _, value := range values {
+		totalVariance += math.Max(value, 0)
+	}
+
+	components := options.Components
+	if components <= 0 || components > numFeatures {
+		components = numFeatures
+	}
+
+	pca.Components = make([][]float64, components)
+	pca.ExplainedVariance = make([]float64, components)
+	pca.ExplainedVarianceRatio = make([]float64, components)
+	for k := range components {
+		pca.Components[k] = orientComponent(vectors[k])
+		pca.ExplainedVariance[k] = math.Max(values[k], 0)
+		if totalVariance > 0 {
+			pca.ExplainedVarianceRatio[k] = pca.ExplainedVariance[k] / totalVariance
+		}
+	}
+
+	return pca, nil
+}
+
+// Eigenvectors are only defined up to sign. The largest loading is made
+// positive so refits on the same data give the same projection.
+func orientComponent(vector []float64) []float64 {
+	largest := 0
+	for i := range vector {
+		if math.Abs(vector[i]) > math.Abs(vector[largest]) {
+			largest = i
+		}
+	}
+
+	component := make([]float64, len(vector))
+	for i := range vector {
+		component[i] = vector[i]
+		if vector[largest] < 0 {
+			component[i] = -vector[i]
+		}
+	}
+	return component
+}
+
+func LoadPCAFromFile(filePath string) (*PCA, error) {
+	pcaJSON, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pca *PCA
+	err = json.Unmarshal(pcaJSON, &pca)
+	if err != nil {
+		return nil, err
+	}
+	if pca == nil {
+		return nil, errors.New("PCA file is empty")
+	}
+
+	return pca, nil
+}
+
+// Transform projects the rows of the dataset onto the fitted components. The
+// dataset must contain every feature the PCA was fitted on.
+func (p *PCA) Transform(dataset *hogwarts.Dataset) ([][]float64, error) {
+	selected, err := dataset.SelectFeatures(p.FeatureNames)
+	if err != nil {
+		return nil, err
+	}
+
+	x := p.prepare(selected.Features)
+	projected := make([][]float64, len(x))
+	for i, row := range x {
+		projected[i] = make([]float64, len(p.Components))
+		for k, component := range p.Components {
+			for j := range row {
+				projected[i][k] += row[j] * component[j]
+			}
+		}
+	}
+	return projected, nil
+}
+
+func (p *PCA) prepare(rows [][]float64) [][]float64 {
+	x := make([][]float64, len(rows))
+	for i := range rows {
+		x[i] = make([]float64, len(rows[i]))
+		for j := range rows[i] {
+			if math.IsNaN(rows[i][j]) {
+				x[i][j] = 0.0
+			} else {
+				x[i][j] = (rows[i][j] - p.Means[j]) / p.Scales[j]
+			}
+		}
+	}
+	return x
+}
@@ -0,0 +1,130 @@
+package decomposition
+
+import (
+	"dslx/internal/hogwarts"
+	"math"
+	"testing"
+)
+
+func assertClose(t *testing.T, name string, got []float64, want []float64, tolerance float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d values, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > tolerance {
+			t.Errorf("%s[%d] = %.15g, want %.15g", name, i, got[i], want[i])
+		}
+	}
+}
+
+// The second feature is twice the first, so all the variance lies along
+// (1, 2)/√5.
+func TestFitPCACollinearFeatures(t *testing.T) {
+	dataset := &hogwarts.Dataset{
+		Features:     [][]float64{{1, 2}, {2, 4}, {3, 6}, {4, 8}, {5, 10}},
+		FeatureNames: []string{"a", "b"},
+		Means:        []float64{3, 6},
+		Stds:         []float64{math.Sqrt(2), math.Sqrt(8)},
+	}
+	pca, err := FitPCA(dataset, PCAOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertClose(t, "first component", pca.Components[0], []float64{1 / math.Sqrt(5), 2 / math.Sqrt(5)}, 1e-12)
+	assertClose(t, "explained variance", pca.ExplainedVariance, []float64{12.5, 0}, 1e-12)
+	assertClose(t, "explained variance ratio", pca.ExplainedVarianceRatio, []float64{1, 0}, 1e-12)
+
+	// The missing value of the last row stands at the mean, so it adds
+	// nothing to the projection.
+	dataset.Features = append(dataset.Features, []float64{4, math.NaN()})
+	projected, err := pca.Transform(dataset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := make([]float64, len(projected))
+	for i := range projected {
+		first[i] = projected[i][0]
+	}
+	root5 := math.Sqrt(5)
+	assertClose(t, "projection", first, []float64{-2 * root5, -root5, 0, root5, 2 * root5, 1 / root5}, 1e-12)
+}
+
+// Two uncorrelated features with variances 4/3 and 400/3. Unscaled, the
+// second dominates; standardized, both explain half.
+func TestFitPCAStandardize(t *testing.T) {
+	dataset := &hogwarts.Dataset{
+		Features:     [][]float64{{1, 10}, {-1, 10}, {1, -10}, {-1, -10}},
+		FeatureNames: []string{"small", "large"},
+		Means:        []float64{0, 0},
+		Stds:         []float64{1, 10},
+	}
+
+	raw, err := FitPCA(dataset, PCAOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertClose(t, "raw first component", raw.Components[0], []float64{0, 1}, 1e-12)
+	assertClose(t, "raw explained variance", raw.ExplainedVariance, []float64{400.0 / 3, 4.0 / 3}, 1e-9)
+	assertClose(t, "raw explained variance ratio", raw.ExplainedVarianceRatio, []float64{100.0 / 101, 1.0 / 101}, 1e-12)
+
+	standardized, err := FitPCA(dataset, PCAOptions{Standardize: true, Components: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(standardized.Components) != 1 {
+		t.Fatalf("kept %d components, want 1", len(standardized.Components))
+	}
+	assertClose(t, "standardized explained variance", standardized.ExplainedVariance, []float64{4.0 / 3}, 1e-12)
+	assertClose(t, "standardized explained variance ratio", standardized.ExplainedVarianceRatio, []float64{0.5}, 1e-12)
+	assertClose(t, "scales", standardized.Scales, []float64{1, 10}, 0)
+}
+
+// Every component has unit length, a positive largest loading, and is
+// orthogonal to the others, and together they explain all the variance.
+func TestFitPCAOnTrainingData(t *testing.T) {
+	dataset, err := hogwarts.LoadDataset("../../datasets/dataset_train.csv", true)
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	pca, err := FitPCA(dataset, PCAOptions{Standardize: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ratioSum := 0.0
+	for k, component := range pca.Components {
+		ratioSum += pca.ExplainedVarianceRatio[k]
+		if k > 0 && pca.ExplainedVariance[k] > pca.ExplainedVariance[k-1] {
+			t.Errorf("component %d explains more than component %d", k, k-1)
+		}
+
+		largest := 0.0
+		for _, loading := range component {
+			if math.Abs(loading) > math.Abs(largest) {
+				largest = loading
+			}
+		}
+		if largest <= 0 {
+			t.Errorf("component %d has largest loading %g", k, largest)
+		}
+
+		for l := range k + 1 {
+			dot := 0.0
+			for j := range component {
+				dot += component[j] * pca.Components[l][j]
+			}
+			want := 0.0
+			if k == l {
+				want = 1
+			}
+			if math.Abs(dot-want) > 1e-10 {
+				t.Errorf("components %d and %d have dot product %g, want %g", k, l, dot, want)
+			}
+		}
+	}
+	if math.Abs(ratioSum-1) > 1e-12 {
+		t.Errorf("explained variance ratios sum to %g", ratioSum)
+	}
+}
@@ -0,0 +1,135 @@
+package featureselection
+
+import (
+	"dslx/internal/hogwarts"
+	"dslx/internal/stats"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+type Criterion int
+
+const (
+	CriterionMutualInformation Criterion = iota
+	CriterionANOVA
+	CriterionChiSquare
+)
+
+func ParseCriterion(name string) (Criterion, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "mi":
+		return CriterionMutualInformation, nil
+	case "anova":
+		return CriterionANOVA, nil
+	case "chi2":
+		return CriterionChiSquare, nil
+	}
+	return 0, fmt.Errorf("unknown ranking criterion %q", name)
+}
+
+type FeatureScore struct {
+	Feature           string
+	MutualInformation float64
+	ANOVA             stats.TestResult
+	ChiSquare         stats.TestResult
+}
+
+type RankingOptions struct {
+	// Binning discretizes the scores for mutual information and the
+	// chi-square test.
+	Binning stats.Binning
+}
+
+// ScoreFeatures scores every feature of the dataset against the house label.
+// Rows without a value for the feature are ignored, and scores that cannot be
+// computed are NaN.
+func ScoreFeatures(dataset *hogwarts.Dataset, options RankingOptions) ([]FeatureScore, error) {
+	scores := make([]FeatureScore, 0, len(dataset.FeatureNames))
+	for i, featureName := range dataset.FeatureNames {
+		score := FeatureScore{
+			Feature:           featureName,
+			MutualInformation: math.NaN(),
+			ANOVA:             stats.TestResult{Statistic: math.NaN(), PValue: math.NaN()},
+			ChiSquare:         stats.TestResult{Statistic: math.NaN(), PValue: math.NaN()},
+		}
+
+		groups := make([][]float64, 0, len(dataset.Houses))
+		for _, house := range dataset.Houses {
+			groups = append(groups, dataset.GetFeatureValuesByHouse(i, house))
+		}
+
+		anova, err := stats.OneWayANOVA(groups)
+		if err != nil && !isMissingData(err) {
+			return nil, err
+		}
+		if err == nil {
+			score.ANOVA = anova
+		}
+
+		table, err := contingencyTable(dataset, i, options.Binning)
+		if err != nil && !isMissingData(err) {
+			return nil, err
+		}
+		if err == nil {
+			if information, err := stats.MutualInformation(table); err == nil {
+				score.MutualInformation = information
+			}
+			if chiSquare, err := stats.ChiSquareIndependence(table); err == nil {
+				score.ChiSquare = chiSquare
+			}
+		}
+
+		scores = append(scores, score)
+	}
+
+	return scores, nil
+}
+
+func isMissingData(err error) bool {
+	return errors.Is(err, stats.ErrNoData) || errors.Is(err, stats.ErrTooFewValues)
+}
+
+// contingencyTable counts the rows of every house in each bin of the feature.
+func contingencyTable(dataset *hogwarts.Dataset, featureIndex int, binning stats.Binning) ([][]float64, error) {
+	edges, err := stats.BinEdges(dataset.GetFeatureValues(featureIndex), binning)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make([][]float64, 0, len(edges)-1)
+	for range len(edges) - 1 {
+		table = append(table, make([]float64, len(dataset.Houses)))
+	}
+	for j, house := range dataset.Houses {
+		counts := stats.Histogram(dataset.GetFeatureValuesByHouse(featureIndex, house), edges)
+		for bin, count := range counts {
+			table[bin][j] = count
+		}
+	}
+	return table, nil
+}
+
+// SortScores orders the scores from the most to the least informative feature
+// under the criterion. Features without a score come last.
+func SortScores(scores []FeatureScore, criterion Criterion) {
+	key := func(score FeatureScore) float64 {
+		switch criterion {
+		case CriterionANOVA:
+			return score.ANOVA.Statistic
+		case CriterionChiSquare:
+			return score.ChiSquare.Statistic
+		}
+		return score.MutualInformation
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		a, b := key(scores[i]), key(scores[j])
+		if math.IsNaN(b) {
+			return !math.IsNaN(a)
+		}
+		return a > b
+	})
+}
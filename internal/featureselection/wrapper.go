@@ -0,0 +1,302 @@
+package featureselection
+
+import (
+	"dslx/internal/hogwarts"
+	"dslx/internal/logisticregression"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+type SelectionMethod int
+
+const (
+	SelectionForward SelectionMethod = iota
+	SelectionBackward
+	SelectionRFE
+)
+
+func ParseSelectionMethod(name string) (SelectionMethod, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "forward":
+		return SelectionForward, nil
+	case "backward":
+		return SelectionBackward, nil
+	case "rfe":
+		return SelectionRFE, nil
+	}
+	return 0, fmt.Errorf("unknown selection method %q", name)
+}
+
+func (m SelectionMethod) String() string {
+	switch m {
+	case SelectionForward:
+		return "forward"
+	case SelectionBackward:
+		return "backward"
+	case SelectionRFE:
+		return "rfe"
+	}
+	return fmt.Sprintf("SelectionMethod(%d)", int(m))
+}
+
+const defaultFolds = 5
+
+type SelectionOptions struct {
+	Method SelectionMethod
+	// Folds is the number of cross-validation folds, 5 when zero.
+	Folds int
+	Seed  int64
+	// MaxFeatures bounds the size of the selected set. Zero means no bound.
+	MaxFeatures int
+	Training    logisticregression.TrainOptions
+	// Log receives one line per evaluated step. Nothing is logged when it is
+	// nil.
+	Log io.Writer
+}
+
+type SelectionResult struct {
+	Features []string
+	// Accuracy is the mean cross-validated accuracy of the selected features.
+	Accuracy float64
+}
+
+// SelectFeatures searches subsets of the candidate features and returns the one
+// with the best cross-validated accuracy. Ties go to the smaller subset.
+func SelectFeatures(dataset *hogwarts.Dataset, candidates []string, options SelectionOptions) (SelectionResult, error) {
+	if len(candidates) == 0 {
+		return SelectionResult{}, errors.New("no candidate features to select from")
+	}
+
+	folds := options.Folds
+	if folds == 0 {
+		folds = defaultFolds
+	}
+	if folds < 2 || folds > len(dataset.Features) {
+		return SelectionResult{}, fmt.Errorf("invalid number of folds %d", folds)
+	}
+
+	log := options.Log
+	if log == nil {
+		log = io.Discard
+	}
+
+	maxFeatures := options.MaxFeatures
+	if maxFeatures <= 0 || maxFeatures > len(candidates) {
+		maxFeatures = len(candidates)
+	}
+
+	selector := &selector{
+		dataset:  dataset,
+		folds:    crossValidationFolds(len(dataset.Features), folds, options.Seed),
+		training: options.Training,
+		log:      log,
+	}
+
+	switch options.Method {
+	case SelectionBackward:
+		return selector.backward(candidates, maxFeatures)
+	case SelectionRFE:
+		return selector.recursiveElimination(candidates, maxFeatures)
+	}
+	return selector.forward(candidates, maxFeatures)
+}
+
+type selector struct {
+	dataset  *hogwarts.Dataset
+	folds    [][]int
+	training logisticregression.TrainOptions
+	log      io.Writer
+}
+
+func (s *selector) forward(candidates []string, maxFeatures int) (SelectionResult, error) {
+	best := SelectionResult{Accuracy: math.Inf(-1)}
+	selected := make([]string, 0, maxFeatures)
+	remaining := append([]string(nil), candidates...)
+
+	for len(selected) < maxFeatures {
+		stepFeature := -1
+		stepAccuracy := math.Inf(-1)
+		for i, feature := range remaining {
+			accuracy, err := s.crossValidate(append(append([]string(nil), selected...), feature))
+			if err != nil {
+				return SelectionResult{}, err
+			}
+			if accuracy > stepAccuracy {
+				stepAccuracy = accuracy
+				stepFeature = i
+			}
+		}
+
+		selected = append(selected, remaining[stepFeature])
+		remaining = append(remaining[:stepFeature], remaining[stepFeature+1:]...)
+		fmt.Fprintf(s.log, "  + %s: accuracy %.4f\n", selected[len(selected)-1], stepAccuracy)
+
+		if stepAccuracy <= best.Accuracy {
+			break
+		}
+		best = SelectionResult{Features: append([]string(nil), selected...), Accuracy: stepAccuracy}
+	}
+
+	return best, nil
+}
+
+func (s *selector) backward(candidates []string, maxFeatures int) (SelectionResult, error) {
+	selected := append([]string(nil), candidates...)
+	accuracy, err := s.crossValidate(selected)
+	if err != nil {
+		return SelectionResult{}, err
+	}
+	fmt.Fprintf(s.log, "  all %d features: accuracy %.4f\n", len(selected), accuracy)
+
+	best := SelectionResult{Accuracy: math.Inf(-1)}
+	if len(selected) <= maxFeatures {
+		best = SelectionResult{Features: append([]string(nil), selected...), Accuracy: accuracy}
+	}
+
+	for len(selected) > 1 {
+		stepFeature := -1
+		stepAccuracy := math.Inf(-1)
+		for i := range selected {
+			without := append(append([]string(nil), selected[:i]...), selected[i+1:]...)
+			accuracy, err := s.crossValidate(without)
+			if err != nil {
+				return SelectionResult{}, err
+			}
+			if accuracy > stepAccuracy {
+				stepAccuracy = accuracy
+				stepFeature = i
+			}
+		}
+
+		fmt.Fprintf(s.log, "  - %s: accuracy %.4f\n", selected[stepFeature], stepAccuracy)
+		selected = append(selected[:stepFeature], selected[stepFeature+1:]...)
+
+		if len(selected) <= maxFeatures && stepAccuracy >= best.Accuracy {
+			best = SelectionResult{Features: append([]string(nil), selected...), Accuracy: stepAccuracy}
+		} else if len(selected) < maxFeatures {
+			break
+		}
+	}
+
+	return best, nil
+}
+
+// recursiveElimination repeatedly drops the feature with the smallest total
+// absolute weight across the classes. Features are standardized before
+// training, so the weights are comparable.
+func (s *selector) recursiveElimination(candidates []string, maxFeatures int) (SelectionResult, error) {
+	selected := append([]string(nil), candidates...)
+	best := SelectionResult{Accuracy: math.Inf(-1)}
+
+	for len(selected) > 0 {
+		if len(selected) <= maxFeatures {
+			accuracy, err := s.crossValidate(selected)
+			if err != nil {
+				return SelectionResult{}, err
+			}
+			fmt.Fprintf(s.log, "  %d features: accuracy %.4f\n", len(selected), accuracy)
+			if accuracy >= best.Accuracy {
+				best = SelectionResult{Features: append([]string(nil), selected...), Accuracy: accuracy}
+			}
+		}
+		if len(selected) == 1 {
+			break
+		}
+
+		dataset, err := s.dataset.SelectFeatures(selected)
+		if err != nil {
+			return SelectionResult{}, err
+		}
+		model, err := logisticregression.TrainNewModelWithOptions(dataset, s.quietTraining())
+		if err != nil {
+			return SelectionResult{}, err
+		}
+
+		importances := make([]float64, len(selected))
+		for _, classWeights := range model.Weights {
+			for j := range selected {
+				// The first weight is the bias.
+				importances[j] += math.Abs(classWeights[j+1])
+			}
+		}
+
+		weakest := 0
+		for j := range importances {
+			if importances[j] < importances[weakest] {
+				weakest = j
+			}
+		}
+		fmt.Fprintf(s.log, "  - %s: weight %.4f\n", selected[weakest], importances[weakest])
+		selected = append(selected[:weakest], selected[weakest+1:]...)
+	}
+
+	return best, nil
+}
+
+func (s *selector) quietTraining() logisticregression.TrainOptions {
+	training := s.training
+	training.Log = nil
+	return training
+}
+
+// crossValidate returns the mean accuracy over the folds of a model trained on
+// the given features.
+func (s *selector) crossValidate(features []string) (float64, error) {
+	dataset, err := s.dataset.SelectFeatures(features)
+	if err != nil {
+		return 0.0, err
+	}
+
+	total := 0.0
+	for i, validationRows := range s.folds {
+		trainingRows := make([]int, 0, len(dataset.Features)-len(validationRows))
+		for j, fold := range s.folds {
+			if j != i {
+				trainingRows = append(trainingRows, fold...)
+			}
+		}
+
+		model, err := logisticregression.TrainNewModelWithOptions(dataset.Subset(trainingRows), s.quietTraining())
+		if err != nil {
+			return 0.0, err
+		}
+
+		validation := dataset.Subset(validationRows)
+		total += Accuracy(model.Predict(validation), validation.Labels)
+	}
+
+	return total / float64(len(s.folds)), nil
+}
+
+func crossValidationFolds(rows int, folds int, seed int64) [][]int {
+	random := rand.New(rand.NewSource(seed))
+	order := random.Perm(rows)
+
+	result := make([][]int, folds)
+	for i, row := range order {
+		result[i%folds] = append(result[i%folds], row)
+	}
+	for _, fold := range result {
+		sort.Ints(fold)
+	}
+	return result
+}
+
+func Accuracy(predictions []string, labels []string) float64 {
+	if len(labels) == 0 {
+		return 0.0
+	}
+
+	correct := 0
+	for i := range labels {
+		if predictions[i] == labels[i] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(labels))
+}
@@ -0,0 +1,128 @@
+package hogwarts
+
+import (
+	"dslx/internal/stats"
+	"errors"
+	"fmt"
+	"math"
+)
+
+type IntervalOptions struct {
+	Level            float64
+	Method           stats.IntervalMethod
+	Resamples        int
+	Seed             int64
+	StratifyByHouses bool
+}
+
+// DescribeWithIntervals renders the same table as String with bootstrap
+// confidence bounds below every statistic except the count.
+func (d *Dataset) DescribeWithIntervals(options IntervalOptions) (string, error) {
+	bootstrapOptions := stats.BootstrapOptions{
+		Resamples: options.Resamples,
+		Seed:      options.Seed,
+	}
+	if options.StratifyByHouses {
+		bootstrapOptions.Strata = d.Labels
+	}
+
+	lowerName := fmt.Sprintf("  %g%% lower", options.Level*100)
+	upperName := fmt.Sprintf("  %g%% upper", options.Level*100)
+
+	rows := make([]statisticsRow, 0)
+	for _, row := range d.statisticsRows() {
+		rows = append(rows, row)
+
+		statistic := d.bootstrapStatistic(row.name)
+		if statistic == nil {
+			continue
+		}
+
+		lower := make([]float64, len(d.FeatureNames))
+		upper := make([]float64, len(d.FeatureNames))
+		for i := range d.FeatureNames {
+			interval, err := stats.BootstrapConfidenceInterval(len(d.Features), d.featureStatistic(i, statistic),
+				options.Level, options.Method, bootstrapOptions)
+			if errors.Is(err, stats.ErrNoData) {
+				lower[i] = math.NaN()
+				upper[i] = math.NaN()
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+
+			lower[i] = interval.Lower
+			upper[i] = interval.Upper
+		}
+
+		rows = append(rows,
+			statisticsRow{name: lowerName, values: lower},
+			statisticsRow{name: upperName, values: upper},
+		)
+	}
+
+	return formatStatisticsTable(d.FeatureNames, rows), nil
+}
+
+// bootstrapStatistic mirrors computeStatistics for the row of the given name,
+// without the unit replacement of a zero standard deviation.
+func (d *Dataset) bootstrapStatistic(name string) func(values []float64, weights []float64) float64 {
+	percentile := func(p float64) func([]float64, []float64) float64 {
+		return func(values []float64, weights []float64) float64 {
+			if weights != nil {
+				return stats.WeightedPercentile(values, weights, p, d.PercentileMethod)
+			}
+			return stats.PercentileWithMethod(values, p, d.PercentileMethod)
+		}
+	}
+
+	switch name {
+	case "Mean":
+		return func(values []float64, weights []float64) float64 {
+			if weights != nil {
+				return stats.WeightedMean(values, weights)
+			}
+			return stats.Mean(values)
+		}
+	case "Std":
+		return func(values []float64, weights []float64) float64 {
+			if weights != nil {
+				return stats.WeightedStd(values, weights, d.Ddof)
+			}
+			return stats.StdWithDdof(values, d.Ddof)
+		}
+	case "Min":
+		return func(values []float64, weights []float64) float64 {
+			return stats.Min(values)
+		}
+	case "25%":
+		return percentile(0.25)
+	case "50%":
+		return percentile(0.5)
+	case "75%":
+		return percentile(0.75)
+	case "Max":
+		return func(values []float64, weights []float64) float64 {
+			return stats.Max(values)
+		}
+	}
+	return nil
+}
+
+func (d *Dataset) featureStatistic(featureIndex int, statistic func([]float64, []float64) float64) stats.IndexStatistic {
+	return func(indices []int) float64 {
+		values := make([]float64, len(indices))
+		var weights []float64
+		if d.Weights != nil {
+			weights = make([]float64, len(indices))
+		}
+		for i, index := range indices {
+			values[i] = d.Features[index][featureIndex]
+			if weights != nil {
+				weights[i] = d.Weights[index]
+			}
+		}
+		return statistic(values, weights)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -18,25 +19,46 @@ const (
 )
 
 type Dataset struct {
-	Features     [][]float64
-	Labels       []string
-	Houses       []string
-	FeatureNames []string
-	Counts       []float64
-	Means        []float64
-	Stds         []float64
-	Mins         []float64
-	Maxs         []float64
-	Q25s         []float64
-	Q50s         []float64
-	Q75s         []float64
+	Features         [][]float64
+	Labels           []string
+	Houses           []string
+	FeatureNames     []string
+	Weights          []float64
+	PercentileMethod stats.PercentileMethod
+	Ddof             int
+	Counts           []float64
+	Means            []float64
+	Stds             []float64
+	Mins             []float64
+	Maxs             []float64
+	Q25s             []float64
+	Q50s             []float64
+	Q75s             []float64
+}
+
+type LoadOptions struct {
+	SkipEmptyHouses  bool
+	Features         []string
+	WeightColumn     string
+	PercentileMethod stats.PercentileMethod
+	Ddof             int
 }
 
 func LoadDataset(filename string, skipEmptyHouses bool) (*Dataset, error) {
-	return LoadDatasetWithFeatures(filename, skipEmptyHouses, nil)
+	return LoadDatasetWithOptions(filename, LoadOptions{SkipEmptyHouses: skipEmptyHouses})
 }
 
 func LoadDatasetWithFeatures(filename string, skipEmptyHouses bool, featuresToSelect []string) (*Dataset, error) {
+	return LoadDatasetWithOptions(filename, LoadOptions{
+		SkipEmptyHouses: skipEmptyHouses,
+		Features:        featuresToSelect,
+	})
+}
+
+func LoadDatasetWithOptions(filename string, options LoadOptions) (*Dataset, error) {
+	skipEmptyHouses := options.SkipEmptyHouses
+	featuresToSelect := options.Features
+
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
@@ -75,8 +97,25 @@ func LoadDatasetWithFeatures(filename string, skipEmptyHouses bool, featuresToSe
 		}
 	}
 
+	weightIndex := -1
+	if options.WeightColumn != "" {
+		for i, header := range headerRow {
+			if header == options.WeightColumn {
+				weightIndex = i
+				break
+			}
+		}
+		if weightIndex == -1 {
+			return nil, fmt.Errorf("weight column %q not found in CSV headers", options.WeightColumn)
+		}
+	}
+
 	features := make([][]float64, 0, len(records)-1)
 	labels := make([]string, 0, len(records)-1)
+	var weights []float64
+	if weightIndex != -1 {
+		weights = make([]float64, 0, len(records)-1)
+	}
 	housesMap := make(map[string]struct{})
 
 	for i := 1; i < len(records); i++ {
@@ -87,6 +126,17 @@ func LoadDatasetWithFeatures(filename string, skipEmptyHouses bool, featuresToSe
 			continue
 		}
 
+		if weightIndex != -1 {
+			if weightIndex >= len(row) {
+				return nil, fmt.Errorf("missing weight on line %d", i+1)
+			}
+			weight, err := strconv.ParseFloat(strings.TrimSpace(row[weightIndex]), 64)
+			if err != nil || weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+				return nil, fmt.Errorf("invalid weight %q on line %d", row[weightIndex], i+1)
+			}
+			weights = append(weights, weight)
+		}
+
 		labels = append(labels, house)
 		housesMap[house] = struct{}{}
 
@@ -119,57 +169,176 @@ func LoadDatasetWithFeatures(filename string, skipEmptyHouses bool, featuresToSe
 	for house := range housesMap {
 		houses = append(houses, house)
 	}
+	sort.Strings(houses)
+
+	dataset := &Dataset{
+		Features:         features,
+		Labels:           labels,
+		Houses:           houses,
+		FeatureNames:     featuresToSelect,
+		Weights:          weights,
+		PercentileMethod: options.PercentileMethod,
+		Ddof:             options.Ddof,
+	}
+	dataset.computeStatistics()
 
-	numSelectedFeatures := len(featureIndices)
-	counts := make([]float64, numSelectedFeatures)
-	for j := range numSelectedFeatures {
-		count := 0.0
-		for i := range features {
-			if !math.IsNaN(features[i][j]) {
-				count++
+	return dataset, nil
+}
+
+// Subset returns the given rows as a new dataset with its own statistics.
+func (d *Dataset) Subset(rows []int) *Dataset {
+	features := make([][]float64, 0, len(rows))
+	labels := make([]string, 0, len(rows))
+	var weights []float64
+	if d.Weights != nil {
+		weights = make([]float64, 0, len(rows))
+	}
+	housesMap := make(map[string]struct{})
+	for _, row := range rows {
+		features = append(features, d.Features[row])
+		labels = append(labels, d.Labels[row])
+		if weights != nil {
+			weights = append(weights, d.Weights[row])
+		}
+		housesMap[d.Labels[row]] = struct{}{}
+	}
+
+	houses := make([]string, 0, len(housesMap))
+	for _, house := range d.Houses {
+		if _, ok := housesMap[house]; ok {
+			houses = append(houses, house)
+		}
+	}
+
+	subset := &Dataset{
+		Features:         features,
+		Labels:           labels,
+		Houses:           houses,
+		FeatureNames:     d.FeatureNames,
+		Weights:          weights,
+		PercentileMethod: d.PercentileMethod,
+		Ddof:             d.Ddof,
+	}
+	subset.computeStatistics()
+	return subset
+}
+
+// SelectFeatures returns a dataset with only the named features, in the given
+// order.
+func (d *Dataset) SelectFeatures(featureNames []string) (*Dataset, error) {
+	featureIndices := make([]int, 0, len(featureNames))
+	for _, featureName := range featureNames {
+		featureIndex := -1
+		for i, name := range d.FeatureNames {
+			if name == featureName {
+				featureIndex = i
+				break
 			}
 		}
-		counts[j] = count
+		if featureIndex == -1 {
+			return nil, fmt.Errorf("feature %q not found in dataset", featureName)
+		}
+		featureIndices = append(featureIndices, featureIndex)
 	}
 
-	means := make([]float64, numSelectedFeatures)
-	stds := make([]float64, numSelectedFeatures)
-	mins := make([]float64, numSelectedFeatures)
-	maxs := make([]float64, numSelectedFeatures)
-	q25s := make([]float64, numSelectedFeatures)
-	q50s := make([]float64, numSelectedFeatures)
-	q75s := make([]float64, numSelectedFeatures)
-
-	for i := range numSelectedFeatures {
-		means[i] = stats.Mean(getFeaureValues(i, features))
-		stds[i] = stats.Std(getFeaureValues(i, features))
-		if stds[i] < 1e-10 {
-			stds[i] = 1.0
+	features := make([][]float64, len(d.Features))
+	for i, row := range d.Features {
+		features[i] = make([]float64, len(featureIndices))
+		for j, featureIndex := range featureIndices {
+			features[i][j] = row[featureIndex]
 		}
-		mins[i] = stats.Min(getFeaureValues(i, features))
-		maxs[i] = stats.Max(getFeaureValues(i, features))
-		q25s[i] = stats.Q25(getFeaureValues(i, features))
-		q50s[i] = stats.Q50(getFeaureValues(i, features))
-		q75s[i] = stats.Q75(getFeaureValues(i, features))
 	}
 
-	return &Dataset{
-		Features:     features,
-		Labels:       labels,
-		Houses:       houses,
-		FeatureNames: featuresToSelect,
-		Counts:       counts,
-		Means:        means,
-		Stds:         stds,
-		Mins:         mins,
-		Maxs:         maxs,
-		Q25s:         q25s,
-		Q50s:         q50s,
-		Q75s:         q75s,
-	}, nil
+	selected := &Dataset{
+		Features:         features,
+		Labels:           d.Labels,
+		Houses:           d.Houses,
+		FeatureNames:     append([]string(nil), featureNames...),
+		Weights:          d.Weights,
+		PercentileMethod: d.PercentileMethod,
+		Ddof:             d.Ddof,
+	}
+	selected.computeStatistics()
+	return selected, nil
+}
+
+func (d *Dataset) computeStatistics() {
+	numFeatures := len(d.FeatureNames)
+	d.Counts = make([]float64, numFeatures)
+	d.Means = make([]float64, numFeatures)
+	d.Stds = make([]float64, numFeatures)
+	d.Mins = make([]float64, numFeatures)
+	d.Maxs = make([]float64, numFeatures)
+	d.Q25s = make([]float64, numFeatures)
+	d.Q50s = make([]float64, numFeatures)
+	d.Q75s = make([]float64, numFeatures)
+
+	for i := range numFeatures {
+		values := d.GetFeatureValues(i)
+
+		count := 0.0
+		for _, value := range values {
+			if !math.IsNaN(value) {
+				count++
+			}
+		}
+		d.Counts[i] = count
+
+		if d.Weights != nil {
+			d.Means[i] = valueOrNaN(stats.WeightedMeanE(values, d.Weights))
+			d.Stds[i] = valueOrNaN(stats.WeightedStdE(values, d.Weights, d.Ddof))
+			d.Q25s[i] = valueOrNaN(stats.WeightedPercentileE(values, d.Weights, 0.25, d.PercentileMethod))
+			d.Q50s[i] = valueOrNaN(stats.WeightedPercentileE(values, d.Weights, 0.5, d.PercentileMethod))
+			d.Q75s[i] = valueOrNaN(stats.WeightedPercentileE(values, d.Weights, 0.75, d.PercentileMethod))
+		} else {
+			d.Means[i] = valueOrNaN(stats.MeanE(values))
+			d.Stds[i] = valueOrNaN(stats.StdWithDdofE(values, d.Ddof))
+			d.Q25s[i] = valueOrNaN(stats.PercentileE(values, 0.25, d.PercentileMethod))
+			d.Q50s[i] = valueOrNaN(stats.PercentileE(values, 0.5, d.PercentileMethod))
+			d.Q75s[i] = valueOrNaN(stats.PercentileE(values, 0.75, d.PercentileMethod))
+		}
+		// A feature without spread keeps unit scale, so standardizing it
+		// only centers it.
+		if d.Stds[i] < 1e-10 {
+			d.Stds[i] = 1.0
+		}
+		d.Mins[i] = valueOrNaN(stats.MinE(values))
+		d.Maxs[i] = valueOrNaN(stats.MaxE(values))
+	}
+}
+
+// Statistics of a feature without any values are stored as NaN, so the
+// remaining columns can still be described.
+func valueOrNaN(value float64, err error) float64 {
+	if err != nil {
+		return math.NaN()
+	}
+	return value
 }
 
 func (d *Dataset) String() string {
+	return formatStatisticsTable(d.FeatureNames, d.statisticsRows())
+}
+
+type statisticsRow struct {
+	name   string
+	values []float64
+}
+
+func (d *Dataset) statisticsRows() []statisticsRow {
+	return []statisticsRow{
+		{"Count", d.Counts},
+		{"Mean", d.Means},
+		{"Std", d.Stds},
+		{"Min", d.Mins},
+		{"25%", d.Q25s},
+		{"50%", d.Q50s},
+		{"75%", d.Q75s},
+		{"Max", d.Maxs},
+	}
+}
+
+func formatStatisticsTable(featureNames []string, rows []statisticsRow) string {
 	const statColumnWidth = 15
 	const minFeatureColumnWidth = 18
 	const terminalWidth = 120
@@ -181,15 +350,15 @@ func (d *Dataset) String() string {
 
 	var result strings.Builder
 
-	for chunkStart := 0; chunkStart < len(d.FeatureNames); chunkStart += featuresPerChunk {
+	for chunkStart := 0; chunkStart < len(featureNames); chunkStart += featuresPerChunk {
 		chunkEnd := chunkStart + featuresPerChunk
-		if chunkEnd > len(d.FeatureNames) {
-			chunkEnd = len(d.FeatureNames)
+		if chunkEnd > len(featureNames) {
+			chunkEnd = len(featureNames)
 		}
 
 		featureColumnWidth := minFeatureColumnWidth
 		for i := chunkStart; i < chunkEnd; i++ {
-			nameLen := len(d.FeatureNames[i])
+			nameLen := len(featureNames[i])
 			if nameLen > featureColumnWidth {
 				featureColumnWidth = nameLen
 			}
@@ -201,57 +370,21 @@ func (d *Dataset) String() string {
 
 		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, ""))
 		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*s ", featureColumnWidth, d.FeatureNames[i]))
-		}
-		result.WriteString("\n")
-
-		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, "Count"))
-		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, d.Counts[i]))
-		}
-		result.WriteString("\n")
-
-		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, "Mean"))
-		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, d.Means[i]))
-		}
-		result.WriteString("\n")
-
-		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, "Std"))
-		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, d.Stds[i]))
+			result.WriteString(fmt.Sprintf("%-*s ", featureColumnWidth, featureNames[i]))
 		}
 		result.WriteString("\n")
 
-		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, "Min"))
-		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, d.Mins[i]))
-		}
-		result.WriteString("\n")
-
-		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, "25%"))
-		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, d.Q25s[i]))
-		}
-		result.WriteString("\n")
-
-		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, "50%"))
-		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, d.Q50s[i]))
-		}
-		result.WriteString("\n")
-
-		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, "75%"))
-		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, d.Q75s[i]))
-		}
-		result.WriteString("\n")
-
-		result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, "Max"))
-		for i := chunkStart; i < chunkEnd; i++ {
-			result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, d.Maxs[i]))
+		for _, row := range rows {
+			result.WriteString(fmt.Sprintf("%-*s", statColumnWidth, row.name))
+			for i := chunkStart; i < chunkEnd; i++ {
+				if math.IsNaN(row.values[i]) {
+					result.WriteString(fmt.Sprintf("%-*s ", featureColumnWidth, ""))
+				} else {
+					result.WriteString(fmt.Sprintf("%-*.6f ", featureColumnWidth, row.values[i]))
+				}
+			}
+			result.WriteString("\n")
 		}
-		result.WriteString("\n")
 	}
 
 	return result.String()
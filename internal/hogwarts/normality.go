@@ -0,0 +1,76 @@
+package hogwarts
+
+import (
+	"dslx/internal/stats"
+	"errors"
+	"math"
+	"strings"
+)
+
+type normalityTest struct {
+	statisticName string
+	pValueName    string
+	run           func([]float64) (stats.TestResult, error)
+}
+
+var normalityTests = []normalityTest{
+	{"Shapiro W", "Shapiro p", stats.ShapiroWilk},
+	{"Anderson A2", "Anderson p", stats.AndersonDarling},
+	{"KS D", "KS p", stats.KolmogorovSmirnov},
+	{"K2", "K2 p", stats.DAgostinoPearson},
+}
+
+// DescribeNormality renders the normality tests for every feature, first over
+// all students and then within each house. Tests that cannot run on a
+// feature, e.g. too few or too many values, are left blank.
+func (d *Dataset) DescribeNormality() (string, error) {
+	var result strings.Builder
+
+	result.WriteString("All houses\n")
+	table, err := d.normalityTable(func(i int) []float64 { return d.GetFeatureValues(i) })
+	if err != nil {
+		return "", err
+	}
+	result.WriteString(table)
+
+	for _, house := range d.Houses {
+		result.WriteString("\n" + house + "\n")
+		table, err := d.normalityTable(func(i int) []float64 { return d.GetFeatureValuesByHouse(i, house) })
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(table)
+	}
+
+	return result.String(), nil
+}
+
+func (d *Dataset) normalityTable(featureValues func(int) []float64) (string, error) {
+	count := make([]float64, len(d.FeatureNames))
+	rows := []statisticsRow{{name: "Count", values: count}}
+	for _, test := range normalityTests {
+		statistics := make([]float64, len(d.FeatureNames))
+		pValues := make([]float64, len(d.FeatureNames))
+		for i := range d.FeatureNames {
+			values := stats.RemoveMissingValues(featureValues(i))
+			count[i] = float64(len(values))
+
+			result, err := test.run(values)
+			if errors.Is(err, stats.ErrTooFewValues) || errors.Is(err, stats.ErrTooManyValues) {
+				statistics[i] = math.NaN()
+				pValues[i] = math.NaN()
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			statistics[i] = result.Statistic
+			pValues[i] = result.PValue
+		}
+		rows = append(rows,
+			statisticsRow{name: test.statisticName, values: statistics},
+			statisticsRow{name: test.pValueName, values: pValues},
+		)
+	}
+	return formatStatisticsTable(d.FeatureNames, rows), nil
+}
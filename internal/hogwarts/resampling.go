@@ -0,0 +1,237 @@
+package hogwarts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// ResamplingMethod balances the houses of a training set.
+type ResamplingMethod int
+
+const (
+	NoResampling ResamplingMethod = iota
+	RandomOversampling
+	RandomUndersampling
+	SMOTEResampling
+)
+
+func ParseResamplingMethod(name string) (ResamplingMethod, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "none", "":
+		return NoResampling, nil
+	case "oversample":
+		return RandomOversampling, nil
+	case "undersample":
+		return RandomUndersampling, nil
+	case "smote":
+		return SMOTEResampling, nil
+	}
+	return NoResampling, fmt.Errorf("unknown resampling method %q", name)
+}
+
+func (m ResamplingMethod) String() string {
+	switch m {
+	case NoResampling:
+		return "none"
+	case RandomOversampling:
+		return "oversample"
+	case RandomUndersampling:
+		return "undersample"
+	case SMOTEResampling:
+		return "smote"
+	}
+	return fmt.Sprintf("ResamplingMethod(%d)", int(m))
+}
+
+// Resample applies method with the seed; neighbours is the k of SMOTE.
+func (d *Dataset) Resample(method ResamplingMethod, neighbours int, seed int64) (*Dataset, error) {
+	switch method {
+	case NoResampling:
+		return d, nil
+	case RandomOversampling:
+		return d.RandomOversample(seed), nil
+	case RandomUndersampling:
+		return d.RandomUndersample(seed), nil
+	case SMOTEResampling:
+		return d.SMOTE(neighbours, seed)
+	}
+	return nil, fmt.Errorf("unknown resampling method %v", method)
+}
+
+// RandomOversample repeats randomly drawn rows of every house until each has
+// as many rows as the largest one. Houses without rows stay empty.
+func (d *Dataset) RandomOversample(seed int64) *Dataset {
+	random := rand.New(rand.NewSource(seed))
+	rowsByHouse := d.rowsByHouse()
+	target := 0
+	for _, rows := range rowsByHouse {
+		target = max(target, len(rows))
+	}
+
+	selected := make([]int, 0, target*len(d.Houses))
+	for _, house := range d.Houses {
+		rows := rowsByHouse[house]
+		if len(rows) == 0 {
+			continue
+		}
+		selected = append(selected, rows...)
+		for range target - len(rows) {
+			selected = append(selected, rows[random.Intn(len(rows))])
+		}
+	}
+	return d.Subset(selected)
+}
+
+// RandomUndersample keeps a random subset of the rows of every house, as many
+// as the smallest house with rows has.
+func (d *Dataset) RandomUndersample(seed int64) *Dataset {
+	random := rand.New(rand.NewSource(seed))
+	rowsByHouse := d.rowsByHouse()
+	target := math.MaxInt
+	for _, rows := range rowsByHouse {
+		target = min(target, len(rows))
+	}
+
+	selected := make([]int, 0, target*len(d.Houses))
+	for _, house := range d.Houses {
+		if len(rowsByHouse[house]) == 0 {
+			continue
+		}
+		rows := append([]int(nil), rowsByHouse[house]...)
+		random.Shuffle(len(rows), func(i, j int) {
+			rows[i], rows[j] = rows[j], rows[i]
+		})
+		kept := rows[:target]
+		sort.Ints(kept)
+		selected = append(selected, kept...)
+	}
+	return d.Subset(selected)
+}
+
+// SMOTE grows every house to the size of the largest one with synthetic rows,
+// each placed at a random point between a row and one of its k nearest
+// neighbours of the same house. Distances use standardized scores over the
+// features both rows have; a score missing from either row stays that of the
+// original row. A house with a single row gets copies of it.
+func (d *Dataset) SMOTE(k int, seed int64) (*Dataset, error) {
+	if k <= 0 {
+		return nil, errors.New("number of neighbours must be positive")
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	rowsByHouse := d.rowsByHouse()
+	target := 0
+	for _, rows := range rowsByHouse {
+		target = max(target, len(rows))
+	}
+
+	features := append([][]float64(nil), d.Features...)
+	labels := append([]string(nil), d.Labels...)
+	var weights []float64
+	if d.Weights != nil {
+		weights = append([]float64(nil), d.Weights...)
+	}
+
+	for _, house := range d.Houses {
+		rows := rowsByHouse[house]
+		if len(rows) == 0 {
+			continue
+		}
+		neighbours := make(map[int][]int)
+		for range target - len(rows) {
+			row := rows[random.Intn(len(rows))]
+			if _, ok := neighbours[row]; !ok {
+				neighbours[row] = d.nearestNeighbours(row, rows, k)
+			}
+
+			synthetic := append([]float64(nil), d.Features[row]...)
+			if len(neighbours[row]) > 0 {
+				neighbour := d.Features[neighbours[row][random.Intn(len(neighbours[row]))]]
+				gap := random.Float64()
+				for j := range synthetic {
+					if !math.IsNaN(synthetic[j]) && !math.IsNaN(neighbour[j]) {
+						synthetic[j] += gap * (neighbour[j] - synthetic[j])
+					}
+				}
+			}
+
+			features = append(features, synthetic)
+			labels = append(labels, house)
+			if weights != nil {
+				weights = append(weights, d.Weights[row])
+			}
+		}
+	}
+
+	resampled := &Dataset{
+		Features:         features,
+		Labels:           labels,
+		Houses:           d.Houses,
+		FeatureNames:     d.FeatureNames,
+		Weights:          weights,
+		PercentileMethod: d.PercentileMethod,
+		Ddof:             d.Ddof,
+	}
+	resampled.computeStatistics()
+	return resampled, nil
+}
+
+func (d *Dataset) rowsByHouse() map[string][]int {
+	rowsByHouse := make(map[string][]int, len(d.Houses))
+	for i, label := range d.Labels {
+		rowsByHouse[label] = append(rowsByHouse[label], i)
+	}
+	return rowsByHouse
+}
+
+func (d *Dataset) nearestNeighbours(row int, candidates []int, k int) []int {
+	type neighbour struct {
+		row      int
+		distance float64
+	}
+
+	neighbours := make([]neighbour, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate == row {
+			continue
+		}
+		distance := d.standardizedDistance(row, candidate)
+		if !math.IsNaN(distance) {
+			neighbours = append(neighbours, neighbour{row: candidate, distance: distance})
+		}
+	}
+	sort.SliceStable(neighbours, func(i, j int) bool {
+		return neighbours[i].distance < neighbours[j].distance
+	})
+
+	nearest := make([]int, 0, k)
+	for i := 0; i < len(neighbours) && i < k; i++ {
+		nearest = append(nearest, neighbours[i].row)
+	}
+	return nearest
+}
+
+// standardizedDistance is the Euclidean distance over the features both rows
+// have, scaled up to all features. It is NaN when they share none.
+func (d *Dataset) standardizedDistance(a int, b int) float64 {
+	sum := 0.0
+	shared := 0
+	for j := range d.FeatureNames {
+		x := d.Features[a][j]
+		y := d.Features[b][j]
+		if math.IsNaN(x) || math.IsNaN(y) {
+			continue
+		}
+		difference := (x - y) / d.Stds[j]
+		sum += difference * difference
+		shared++
+	}
+	if shared == 0 {
+		return math.NaN()
+	}
+	return math.Sqrt(sum * float64(len(d.FeatureNames)) / float64(shared))
+}
@@ -0,0 +1,168 @@
+package hogwarts
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Gryffindor has six rows, Hufflepuff three on a line, Ravenclaw one and
+// Slytherin none.
+func newImbalancedDataset() *Dataset {
+	dataset := &Dataset{
+		Features: [][]float64{
+			{0, 0}, {1, 0}, {0, 1}, {1, 1}, {2, 2}, {3, 1},
+			{5, 5}, {6, 7}, {8, 11},
+			{-3, 4},
+		},
+		Labels: []string{
+			"Gryffindor", "Gryffindor", "Gryffindor", "Gryffindor", "Gryffindor", "Gryffindor",
+			"Hufflepuff", "Hufflepuff", "Hufflepuff",
+			"Ravenclaw",
+		},
+		Houses:       []string{"Gryffindor", "Hufflepuff", "Ravenclaw", "Slytherin"},
+		FeatureNames: []string{"x", "y"},
+	}
+	dataset.computeStatistics()
+	return dataset
+}
+
+func houseCounts(dataset *Dataset) map[string]int {
+	counts := make(map[string]int)
+	for _, label := range dataset.Labels {
+		counts[label]++
+	}
+	return counts
+}
+
+func TestResampleBalancesHouses(t *testing.T) {
+	dataset := newImbalancedDataset()
+	tests := []struct {
+		method ResamplingMethod
+		want   map[string]int
+	}{
+		{NoResampling, map[string]int{"Gryffindor": 6, "Hufflepuff": 3, "Ravenclaw": 1}},
+		{RandomOversampling, map[string]int{"Gryffindor": 6, "Hufflepuff": 6, "Ravenclaw": 6}},
+		{RandomUndersampling, map[string]int{"Gryffindor": 1, "Hufflepuff": 1, "Ravenclaw": 1}},
+		{SMOTEResampling, map[string]int{"Gryffindor": 6, "Hufflepuff": 6, "Ravenclaw": 6}},
+	}
+	for _, test := range tests {
+		resampled, err := dataset.Resample(test.method, 2, 1)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.method, err)
+		}
+		if got := houseCounts(resampled); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.method, got, test.want)
+		}
+	}
+}
+
+// Random resampling only draws rows of the original dataset, and keeps every
+// row when oversampling.
+func TestRandomResamplingDrawsOriginalRows(t *testing.T) {
+	dataset := newImbalancedDataset()
+	original := make(map[[2]float64]string)
+	for i, row := range dataset.Features {
+		original[[2]float64{row[0], row[1]}] = dataset.Labels[i]
+	}
+
+	for name, resampled := range map[string]*Dataset{
+		"oversample":  dataset.RandomOversample(3),
+		"undersample": dataset.RandomUndersample(3),
+	} {
+		seen := make(map[[2]float64]bool)
+		for i, row := range resampled.Features {
+			key := [2]float64{row[0], row[1]}
+			if label, ok := original[key]; !ok || label != resampled.Labels[i] {
+				t.Errorf("%s: row %v of %s is not an original row", name, row, resampled.Labels[i])
+			}
+			seen[key] = true
+		}
+		if name == "oversample" && len(seen) != len(original) {
+			t.Errorf("oversample: kept %d of %d rows", len(seen), len(original))
+		}
+	}
+}
+
+// Every synthetic row lies on the segment from a row of its house to one of
+// that row's k nearest neighbours.
+func TestSMOTEPlacesRowsBetweenNeighbours(t *testing.T) {
+	dataset := newImbalancedDataset()
+	const k = 1
+	resampled, err := dataset.SMOTE(k, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rowsByHouse := dataset.rowsByHouse()
+
+	for i := len(dataset.Features); i < len(resampled.Features); i++ {
+		synthetic, house := resampled.Features[i], resampled.Labels[i]
+		between := false
+		for _, row := range rowsByHouse[house] {
+			neighbours := dataset.nearestNeighbours(row, rowsByHouse[house], k)
+			if len(neighbours) == 0 {
+				between = between || reflect.DeepEqual(synthetic, dataset.Features[row])
+			}
+			for _, neighbour := range neighbours {
+				between = between || onSegment(synthetic, dataset.Features[row], dataset.Features[neighbour])
+			}
+		}
+		if !between {
+			t.Errorf("synthetic %s row %v is not between neighbours", house, synthetic)
+		}
+	}
+}
+
+func onSegment(point []float64, from []float64, to []float64) bool {
+	gap := math.NaN()
+	for j := range point {
+		if from[j] != to[j] {
+			gap = (point[j] - from[j]) / (to[j] - from[j])
+			break
+		}
+	}
+	if !(gap >= 0 && gap <= 1) {
+		return false
+	}
+	for j := range point {
+		if math.Abs(from[j]+gap*(to[j]-from[j])-point[j]) > 1e-12 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestResampleIsDeterministicForASeed(t *testing.T) {
+	dataset := newImbalancedDataset()
+	for _, method := range []ResamplingMethod{RandomOversampling, RandomUndersampling, SMOTEResampling} {
+		first, _ := dataset.Resample(method, 2, 42)
+		second, _ := dataset.Resample(method, 2, 42)
+		if !reflect.DeepEqual(first.Features, second.Features) || !reflect.DeepEqual(first.Labels, second.Labels) {
+			t.Errorf("%s: seed 42 gave two different datasets", method)
+		}
+	}
+
+	first, _ := dataset.SMOTE(2, 1)
+	second, _ := dataset.SMOTE(2, 2)
+	if reflect.DeepEqual(first.Features, second.Features) {
+		t.Error("smote: seeds 1 and 2 gave the same rows")
+	}
+}
+
+func TestSMOTERejectsNonPositiveNeighbours(t *testing.T) {
+	if _, err := newImbalancedDataset().SMOTE(0, 1); err == nil {
+		t.Error("expected an error for zero neighbours")
+	}
+}
+
+func TestParseResamplingMethod(t *testing.T) {
+	for _, method := range []ResamplingMethod{NoResampling, RandomOversampling, RandomUndersampling, SMOTEResampling} {
+		parsed, err := ParseResamplingMethod(method.String())
+		if err != nil || parsed != method {
+			t.Errorf("%s: got %s, %v", method, parsed, err)
+		}
+	}
+	if _, err := ParseResamplingMethod("adasyn"); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
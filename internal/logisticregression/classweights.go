@@ -0,0 +1,113 @@
+package logisticregression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ClassWeights scale the sample weight of every row by a weight of its class.
+type ClassWeights struct {
+	// Balanced weights every class inversely to its total sample weight, so
+	// all classes count equally. For one-vs-rest it balances each house
+	// against the rest of its own subproblem.
+	Balanced bool
+	// Weights holds user weights by house. Houses it leaves out weigh 1.
+	Weights map[string]float64
+}
+
+// ParseClassWeights reads "none", "balanced" or a list of house weights such
+// as "Gryffindor=2,Slytherin=1.5".
+func ParseClassWeights(spec string) (ClassWeights, error) {
+	spec = strings.TrimSpace(spec)
+	switch strings.ToLower(spec) {
+	case "", "none":
+		return ClassWeights{}, nil
+	case "balanced":
+		return ClassWeights{Balanced: true}, nil
+	}
+
+	weights := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		house, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return ClassWeights{}, fmt.Errorf("invalid class weight %q, expected house=weight", entry)
+		}
+		house = strings.TrimSpace(house)
+		if house == "" {
+			return ClassWeights{}, fmt.Errorf("invalid class weight %q, missing the house", entry)
+		}
+		if _, ok := weights[house]; ok {
+			return ClassWeights{}, fmt.Errorf("class weight of %s given twice", house)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return ClassWeights{}, fmt.Errorf("invalid class weight %q: %w", entry, err)
+		}
+		weights[house] = weight
+	}
+
+	classWeights := ClassWeights{Weights: weights}
+	if err := classWeights.validate(); err != nil {
+		return ClassWeights{}, err
+	}
+	return classWeights, nil
+}
+
+func (c ClassWeights) validate() error {
+	if c.Balanced && len(c.Weights) > 0 {
+		return errors.New("class weights cannot be both balanced and user-provided")
+	}
+	for house, weight := range c.Weights {
+		if !(weight > 0) || math.IsInf(weight, 1) {
+			return fmt.Errorf("class weight of %s must be positive", house)
+		}
+	}
+	return nil
+}
+
+// apply returns the row weights scaled by the class weights. positive names
+// the house of a one-vs-rest subproblem, where balancing weighs that house
+// against all others; it is empty for softmax.
+func (c ClassWeights) apply(rowWeights []float64, labels []string, positive string) []float64 {
+	if !c.Balanced && len(c.Weights) == 0 {
+		return rowWeights
+	}
+
+	class := func(label string) string {
+		if positive != "" && label != positive {
+			return ""
+		}
+		return label
+	}
+
+	var factors map[string]float64
+	if c.Balanced {
+		totals := make(map[string]float64)
+		total := 0.0
+		for i, label := range labels {
+			totals[class(label)] += rowWeights[i]
+			total += rowWeights[i]
+		}
+		factors = make(map[string]float64, len(totals))
+		for name, classTotal := range totals {
+			if classTotal > 0 {
+				factors[name] = total / (float64(len(totals)) * classTotal)
+			}
+		}
+	}
+
+	weighted := make([]float64, len(rowWeights))
+	for i, label := range labels {
+		factor := 1.0
+		if c.Balanced {
+			factor = factors[class(label)]
+		} else if weight, ok := c.Weights[label]; ok {
+			factor = weight
+		}
+		weighted[i] = rowWeights[i] * factor
+	}
+	return weighted
+}
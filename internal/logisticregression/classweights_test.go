@@ -0,0 +1,60 @@
+package logisticregression
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseClassWeights(t *testing.T) {
+	tests := []struct {
+		spec string
+		want ClassWeights
+	}{
+		{"", ClassWeights{}},
+		{" None ", ClassWeights{}},
+		{"balanced", ClassWeights{Balanced: true}},
+		{"Gryffindor=2, Slytherin = 1.5", ClassWeights{Weights: map[string]float64{"Gryffindor": 2, "Slytherin": 1.5}}},
+	}
+	for _, test := range tests {
+		got, err := ParseClassWeights(test.spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %+v, want %+v", test.spec, got, test.want)
+		}
+	}
+}
+
+func TestParseClassWeightsRejectsBadSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"Gryffindor",
+		"Gryffindor=",
+		"Gryffindor=heavy",
+		"=2",
+		"Gryffindor=2,",
+		"Gryffindor=0",
+		"Gryffindor=-1",
+		"Gryffindor=NaN",
+		"Gryffindor=+Inf",
+		"Gryffindor=2,Gryffindor=3",
+	} {
+		if weights, err := ParseClassWeights(spec); err == nil {
+			t.Errorf("%q: got %+v, want an error", spec, weights)
+		}
+	}
+}
+
+func TestClassWeightsApply(t *testing.T) {
+	labels := []string{"A", "A", "A", "B"}
+	rowWeights := []float64{1, 1, 1, 1}
+
+	balanced := ClassWeights{Balanced: true}
+	assertClose(t, "softmax", balanced.apply(rowWeights, labels, ""), []float64{2.0 / 3.0, 2.0 / 3.0, 2.0 / 3.0, 2}, 1e-15)
+	// For one-vs-rest, B is balanced against the rest, which is only A here.
+	assertClose(t, "one-vs-rest", balanced.apply(rowWeights, labels, "B"), []float64{2.0 / 3.0, 2.0 / 3.0, 2.0 / 3.0, 2}, 1e-15)
+
+	user := ClassWeights{Weights: map[string]float64{"B": 3}}
+	assertClose(t, "user", user.apply(rowWeights, labels, ""), []float64{1, 1, 1, 3}, 0)
+}
@@ -1,46 +1,393 @@
 package logisticregression
 
 import (
+	"bytes"
 	"dslx/internal/hogwarts"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"math/rand"
 	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DefaultFeatureNames are the courses used for training when no feature
+// selection is run, and for models saved before the features were recorded.
+var DefaultFeatureNames = []string{
+	"Astronomy",
+	"Herbology",
+	"Defense Against the Dark Arts",
+	"Divination",
+	"Muggle Studies",
+	"Ancient Runes",
+	"Charms",
+}
+
+type Strategy int
+
+const (
+	// OneVsRest trains an independent sigmoid classifier per house.
+	OneVsRest Strategy = iota
+	// Softmax trains a single multinomial model with cross-entropy loss.
+	Softmax
 )
 
+func ParseStrategy(name string) (Strategy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "one-vs-rest", "ovr":
+		return OneVsRest, nil
+	case "softmax", "multinomial":
+		return Softmax, nil
+	}
+	return OneVsRest, fmt.Errorf("unknown strategy %q", name)
+}
+
+func (s Strategy) String() string {
+	switch s {
+	case OneVsRest:
+		return "one-vs-rest"
+	case Softmax:
+		return "softmax"
+	}
+	return fmt.Sprintf("Strategy(%d)", int(s))
+}
+
 type Model struct {
-	LabelNames []string    `json:"label_names"`
-	Weights    [][]float64 `json:"weights"`
-	Means      []float64   `json:"means"`
-	Stds       []float64   `json:"stds"`
+	// Type is the training strategy. Models saved before it was recorded are
+	// one-vs-rest.
+	Type         string      `json:"type"`
+	FeatureNames []string    `json:"feature_names"`
+	LabelNames   []string    `json:"label_names"`
+	Weights      [][]float64 `json:"weights"`
+	// Means and Stds standardize the features. Stds divide by the count minus
+	// StdDdof, and a feature whose std is below 1e-10 gets a std of 1, so it
+	// is only centered.
+	Means   []float64 `json:"means"`
+	Stds    []float64 `json:"stds"`
+	StdDdof int       `json:"std_ddof"`
+	// The training settings, absent from models saved before they were
+	// recorded.
+	Solver       string           `json:"solver,omitempty"`
+	LearningRate float64          `json:"learning_rate,omitempty"`
+	Optimizer    *OptimizerConfig `json:"optimizer,omitempty"`
+	Schedule     *Schedule        `json:"schedule,omitempty"`
+	// Regularization is absent for models trained without a penalty.
+	Regularization *Regularization `json:"regularization,omitempty"`
+	Diagnostics    []Diagnostics   `json:"diagnostics,omitempty"`
+}
+
+type TrainOptions struct {
+	LearningRate float64
+	// Iterations is the number of epochs, full passes over the rows.
+	Iterations int
+	// BatchSize is the number of rows per gradient step: 0 for full-batch
+	// gradient descent, 1 for stochastic gradient descent.
+	BatchSize int
+	// Seed drives the shuffling of rows between mini-batch epochs.
+	Seed     int64
+	Strategy Strategy
+	// Solver picks the minimization method. Newton and LBFGS always use the
+	// full batch and ignore BatchSize, Optimizer and LearningRate; Iterations
+	// bounds their iterations.
+	Solver Solver
+	// Optimizer is plain SGD when unset, which is DefaultOptimizerConfig(SGD).
+	// Other kinds take their hyperparameters literally, so start from
+	// DefaultOptimizerConfig for the usual ones.
+	Optimizer OptimizerConfig
+	// Schedule varies LearningRate over the epochs. It is constant when unset;
+	// DefaultSchedule gives the usual parameters of the other kinds.
+	Schedule Schedule
+	// GradientTolerance is the gradient norm below which training counts as
+	// converged, and at which Newton and LBFGS stop. Zero means 1e-6.
+	GradientTolerance float64
+	// ClassWeights apply to the training rows, not to the validation rows.
+	ClassWeights ClassWeights
+	// Regularization with an L1 part requires GradientDescent. Its proximal
+	// step gives exact zeros with SGD; adaptive optimizers rarely reach them.
+	Regularization Regularization
+	// Tolerance stops training once the relative change of the cost between
+	// iterations falls below it. Zero disables it.
+	Tolerance float64
+	// ValidationFraction holds out that share of the rows for early stopping
+	// with GradientDescent. Zero disables early stopping.
+	ValidationFraction float64
+	// Patience is the number of epochs without improvement of the monitored
+	// validation metric before training stops and restores the best weights.
+	// Zero means 10.
+	Patience int
+	Monitor  Monitor
+	// Resampling balances the houses of the training rows, after the
+	// validation split. SMOTENeighbours is the k of SMOTE, 5 when zero.
+	Resampling      hogwarts.ResamplingMethod
+	SMOTENeighbours int
+	// Workers bounds how many one-vs-rest classifiers train at once, the
+	// number of CPUs when zero.
+	Workers int
+	// Log receives the training progress every LogEvery epochs, 100 when
+	// zero. Nothing is logged when it is nil.
+	Log      io.Writer
+	LogEvery int
 }
 
 func TrainNewModel(dataset *hogwarts.Dataset, alhpha float64, iteractions int) *Model {
-	x := fillMissingValues(dataset.Features, dataset.Means)
-	normalizeFeatures(x, dataset.Means, dataset.Stds)
-	addBiasTerm(x)
-
-	weights := make([][]float64, 0, len(dataset.Houses))
-	labelNames := make([]string, 0, len(dataset.Houses))
-	for _, house := range dataset.Houses {
-		labelNames = append(labelNames, house)
-		y := make([]float64, 0, len(dataset.Labels))
-		for _, label := range dataset.Labels {
-			if label == house {
-				y = append(y, 1.0)
-			} else {
-				y = append(y, 0.0)
+	// Only resampling can fail, and these options do not resample.
+	model, _ := trainNewModel(dataset, TrainOptions{
+		LearningRate: alhpha,
+		Iterations:   iteractions,
+		Log:          os.Stdout,
+	})
+	return model
+}
+
+func TrainNewModelWithOptions(dataset *hogwarts.Dataset, options TrainOptions) (*Model, error) {
+	if len(dataset.Features) == 0 {
+		return nil, errors.New("cannot train on an empty dataset")
+	}
+	if options.LearningRate <= 0 {
+		return nil, errors.New("learning rate must be positive")
+	}
+	if options.Iterations <= 0 {
+		return nil, errors.New("number of iterations must be positive")
+	}
+	if options.BatchSize < 0 {
+		return nil, errors.New("batch size must not be negative")
+	}
+	if err := options.Optimizer.validate(); err != nil {
+		return nil, err
+	}
+	if err := options.Schedule.validate(); err != nil {
+		return nil, err
+	}
+	if options.Workers < 0 {
+		return nil, errors.New("number of workers must not be negative")
+	}
+	if options.LogEvery < 0 {
+		return nil, errors.New("logging interval must not be negative")
+	}
+	if options.Solver < GradientDescent || options.Solver > LBFGS {
+		return nil, fmt.Errorf("unknown solver %v", options.Solver)
+	}
+	if options.GradientTolerance < 0 {
+		return nil, errors.New("gradient tolerance must not be negative")
+	}
+	if options.Tolerance < 0 {
+		return nil, errors.New("tolerance must not be negative")
+	}
+	if options.ValidationFraction < 0 || options.ValidationFraction >= 1 {
+		return nil, errors.New("validation fraction must be in [0, 1)")
+	}
+	if options.ValidationFraction > 0 && options.Solver != GradientDescent {
+		return nil, errors.New("early stopping requires the gradient-descent solver")
+	}
+	if options.ValidationFraction > 0 && len(dataset.Features) < 2 {
+		return nil, errors.New("early stopping needs at least two rows")
+	}
+	if options.Patience < 0 {
+		return nil, errors.New("patience must not be negative")
+	}
+	if options.Monitor < ValidationLoss || options.Monitor > ValidationAccuracy {
+		return nil, fmt.Errorf("unknown monitor %v", options.Monitor)
+	}
+	if err := options.Regularization.validate(); err != nil {
+		return nil, err
+	}
+	if err := options.ClassWeights.validate(); err != nil {
+		return nil, err
+	}
+	if l1, _ := options.Regularization.strengths(); l1 > 0 && options.Solver != GradientDescent {
+		return nil, fmt.Errorf("%s regularization requires the gradient-descent solver", options.Regularization.Type)
+	}
+
+	if options.Resampling < hogwarts.NoResampling || options.Resampling > hogwarts.SMOTEResampling {
+		return nil, fmt.Errorf("unknown resampling method %v", options.Resampling)
+	}
+	if options.SMOTENeighbours < 0 {
+		return nil, errors.New("number of SMOTE neighbours must not be negative")
+	}
+
+	return trainNewModel(dataset, options)
+}
+
+func trainNewModel(dataset *hogwarts.Dataset, options TrainOptions) (*Model, error) {
+	log := options.Log
+	if log == nil {
+		log = io.Discard
+	}
+
+	options.Optimizer = options.Optimizer.normalized()
+	options.Schedule = options.Schedule.normalized()
+	if options.LogEvery == 0 {
+		options.LogEvery = defaultLogEvery
+	}
+
+	training := dataset
+	var validation *hogwarts.Dataset
+	if options.ValidationFraction > 0 {
+		training, validation = splitValidation(dataset, options.ValidationFraction, options.Seed)
+	}
+
+	// Only the training rows are resampled, so no validation row is
+	// duplicated or used for synthetic rows, and the normalization keeps the
+	// statistics of the rows as they were.
+	means, stds := normalizationParameters(training)
+	neighbours := options.SMOTENeighbours
+	if neighbours == 0 {
+		neighbours = defaultSMOTENeighbours
+	}
+	training, err := training.Resample(options.Resampling, neighbours, options.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	x := designMatrix(training.Features, means, stds)
+	_, blockSize := x.Dims()
+	rowWeights := sampleWeights(training)
+	var validationX *mat.Dense
+	if validation != nil {
+		validationX = designMatrix(validation.Features, means, stds)
+	}
+
+	if options.Strategy == Softmax {
+		problem := newSoftmaxObjective(x, training.Labels, dataset.Houses,
+			options.ClassWeights.apply(rowWeights, training.Labels, ""))
+		var validationProblem objective
+		if validation != nil {
+			validationProblem = newSoftmaxObjective(validationX, validation.Labels, dataset.Houses, sampleWeights(validation))
+		}
+
+		weights, diagnostics := solve(regularize(problem, options.Regularization, blockSize), validationProblem, options, log)
+		model := &Model{
+			Type:         Softmax.String(),
+			FeatureNames: dataset.FeatureNames,
+			LabelNames:   append([]string(nil), dataset.Houses...),
+			Weights:      splitWeights(weights, len(dataset.Houses)),
+			Means:        means,
+			Stds:         stds,
+			StdDdof:      dataset.Ddof,
+			Diagnostics:  []Diagnostics{diagnostics},
+		}
+		model.recordSolver(options)
+		return model, nil
+	}
+
+	// The houses train concurrently, each logging into its own buffer. The
+	// buffers are flushed in house order as soon as all earlier houses are
+	// done, so the log reads as if they had trained one after another.
+	houseCount := len(dataset.Houses)
+	weights := make([][]float64, houseCount)
+	allDiagnostics := make([]Diagnostics, houseCount)
+	logs := make([]bytes.Buffer, houseCount)
+	done := make([]chan struct{}, houseCount)
+	for k := range done {
+		done[k] = make(chan struct{})
+	}
+
+	jobs := make(chan int)
+	workers := options.Workers
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	for range min(workers, houseCount) {
+		go func() {
+			for k := range jobs {
+				house := dataset.Houses[k]
+				problem := newBinaryObjective(x, training.Labels, house,
+					options.ClassWeights.apply(rowWeights, training.Labels, house))
+				var validationProblem objective
+				if validation != nil {
+					validationProblem = newBinaryObjective(validationX, validation.Labels, house, sampleWeights(validation))
+				}
+
+				fmt.Fprintf(&logs[k], "Training %s\n", house)
+				houseWeights, diagnostics := solve(regularize(problem, options.Regularization, blockSize), validationProblem, options, &logs[k])
+				diagnostics.Label = house
+				weights[k] = houseWeights
+				allDiagnostics[k] = diagnostics
+				close(done[k])
 			}
+		}()
+	}
+	go func() {
+		for k := range houseCount {
+			jobs <- k
 		}
-		weights = append(weights, gradientDescent(x, y, alhpha, iteractions))
+		close(jobs)
+	}()
+
+	for k := range houseCount {
+		<-done[k]
+		logs[k].WriteTo(log)
 	}
 
-	return &Model{
-		LabelNames: labelNames,
-		Weights:    weights,
-		Means:      dataset.Means,
-		Stds:       dataset.Stds,
+	model := &Model{
+		Type:         OneVsRest.String(),
+		FeatureNames: dataset.FeatureNames,
+		LabelNames:   append([]string(nil), dataset.Houses...),
+		Weights:      weights,
+		Means:        means,
+		Stds:         stds,
+		StdDdof:      dataset.Ddof,
+		Diagnostics:  allDiagnostics,
 	}
+	model.recordSolver(options)
+	return model, nil
+}
+
+// splitValidation holds out a random share of the rows, at least one, for
+// early stopping.
+func splitValidation(dataset *hogwarts.Dataset, fraction float64, seed int64) (*hogwarts.Dataset, *hogwarts.Dataset) {
+	permutation := rand.New(rand.NewSource(seed)).Perm(len(dataset.Features))
+	validationCount := int(math.Round(fraction * float64(len(permutation))))
+	validationCount = max(1, min(validationCount, len(permutation)-1))
+
+	validationRows := permutation[:validationCount]
+	trainingRows := permutation[validationCount:]
+	sort.Ints(validationRows)
+	sort.Ints(trainingRows)
+	return dataset.Subset(trainingRows), dataset.Subset(validationRows)
+}
+
+func (m *Model) recordSolver(options TrainOptions) {
+	m.Solver = options.Solver.String()
+	if options.Solver == GradientDescent {
+		optimizer := options.Optimizer
+		m.LearningRate = options.LearningRate
+		m.Optimizer = &optimizer
+		if !options.Schedule.isConstant() {
+			schedule := options.Schedule
+			m.Schedule = &schedule
+		}
+	}
+	if l1, l2 := options.Regularization.strengths(); l1 > 0 || l2 > 0 {
+		regularization := options.Regularization
+		if regularization.Type != ElasticNet {
+			regularization.L1Ratio = 0
+		}
+		m.Regularization = &regularization
+	}
+}
+
+// A feature without any values has NaN statistics, which cannot be stored in
+// JSON. It is centered at zero with unit scale instead, so it stays constant.
+func normalizationParameters(dataset *hogwarts.Dataset) ([]float64, []float64) {
+	means := make([]float64, len(dataset.Means))
+	stds := make([]float64, len(dataset.Stds))
+	for i := range means {
+		means[i] = dataset.Means[i]
+		stds[i] = dataset.Stds[i]
+		if math.IsNaN(means[i]) || math.IsNaN(stds[i]) {
+			means[i] = 0.0
+			stds[i] = 1.0
+		}
+	}
+	return means, stds
 }
 
 func LoadModelFromFile(filePath string) (*Model, error) {
@@ -54,111 +401,259 @@ func LoadModelFromFile(filePath string) (*Model, error) {
 	if err != nil {
 		return nil, err
 	}
+	if model == nil {
+		return nil, errors.New("model file is empty")
+	}
+
+	if len(model.FeatureNames) == 0 {
+		model.FeatureNames = DefaultFeatureNames
+	}
+	if model.Type == "" {
+		model.Type = OneVsRest.String()
+	}
+	if _, err := ParseStrategy(model.Type); err != nil {
+		return nil, err
+	}
 
 	return model, nil
 }
 
+// Predict returns the most probable label of each row. Rows without any class
+// probability get DefaultUnknownLabel.
 func (m *Model) Predict(dataset *hogwarts.Dataset) []string {
-	x := fillMissingValues(dataset.Features, m.Means)
-	normalizeFeatures(x, m.Means, m.Stds)
-	addBiasTerm(x)
-
-	labelNames := make([]string, 0, len(x))
-	for i := range x {
-		maxPrediction := 0.0
-		maxPredictionLabel := ""
-		for j := range m.Weights {
-			prediction := predict(x[i], m.Weights[j])
-			if prediction > maxPrediction {
-				maxPrediction = prediction
-				maxPredictionLabel = m.LabelNames[j]
-			}
+	decisions, _ := m.PredictWithRejection(dataset, RejectOptions{})
+
+	labelNames := make([]string, len(decisions))
+	for i, decision := range decisions {
+		labelNames[i] = decision.Label
+	}
+	return labelNames
+}
+
+// PredictProba returns one row of class probabilities per dataset row, with
+// columns in the order of m.LabelNames.
+func (m *Model) PredictProba(dataset *hogwarts.Dataset) *mat.Dense {
+	x := designMatrix(dataset.Features, m.Means, m.Stds)
+	_, size := x.Dims()
+	weights := mat.NewDense(len(m.Weights), size, nil)
+	for k, classWeights := range m.Weights {
+		weights.SetRow(k, classWeights)
+	}
+
+	var probabilities mat.Dense
+	probabilities.Mul(x, weights.T())
+	rows, _ := probabilities.Dims()
+	for i := 0; i < rows; i++ {
+		m.probabilitiesInPlace(probabilities.RawRowView(i))
+	}
+	return &probabilities
+}
+
+// Prediction is a label together with its predicted probability.
+type Prediction struct {
+	Label       string
+	Probability float64
+}
+
+// PredictTopK returns the k most probable labels of each row, most probable
+// first. k is capped at the number of labels.
+func (m *Model) PredictTopK(dataset *hogwarts.Dataset, k int) ([][]Prediction, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+	k = min(k, len(m.LabelNames))
+
+	probabilities := m.PredictProba(dataset)
+	rows, _ := probabilities.Dims()
+	predictions := make([][]Prediction, rows)
+	for i := range predictions {
+		ranked := make([]Prediction, len(m.LabelNames))
+		for j, label := range m.LabelNames {
+			ranked[j] = Prediction{Label: label, Probability: probabilities.At(i, j)}
 		}
-		labelNames = append(labelNames, maxPredictionLabel)
+		sort.SliceStable(ranked, func(a, b int) bool {
+			return ranked[a].Probability > ranked[b].Probability
+		})
+		predictions[i] = ranked[:k]
 	}
+	return predictions, nil
+}
 
-	return labelNames
+// probabilitiesInPlace turns the class scores of one row into probabilities.
+// One-vs-rest sigmoids are rescaled to sum to one.
+func (m *Model) probabilitiesInPlace(scores []float64) {
+	if m.Type == Softmax.String() {
+		softmaxInPlace(scores)
+		return
+	}
+
+	sum := 0.0
+	for j, score := range scores {
+		scores[j] = sigmoid(score)
+		sum += scores[j]
+	}
+	if sum > 0 {
+		floats.Scale(1/sum, scores)
+	}
 }
 
-func gradientDescent(x [][]float64, y []float64, alhpha float64, iteractions int) []float64 {
-	weights := make([]float64, len(x[0]))
-	for iter := 0; iter < iteractions; iter++ {
-		gradients := make([]float64, len(weights))
-		for i := range len(y) {
-			prediction := predict(x[i], weights)
-			for j := range len(weights) {
-				gradients[j] += (prediction - y[i]) * x[i][j]
+// gradientDescent runs up to options.Iterations epochs over the rows. Each
+// epoch takes one step per batch of options.BatchSize rows, shuffled with the
+// seed unless the whole dataset forms a single batch. With a validation
+// problem it stops early and returns the best weights seen.
+func gradientDescent(problem objective, validation objective, options TrainOptions, log io.Writer) ([]float64, Diagnostics) {
+	weights := make([]float64, problem.size())
+	gradient := make([]float64, len(weights))
+	optimizer := NewOptimizer(options.Optimizer, len(weights))
+	regularized, proximal := problem.(*regularizedObjective)
+	proximal = proximal && regularized.l1 > 0
+
+	rows := make([]int, problem.rowCount())
+	for i := range rows {
+		rows[i] = i
+	}
+
+	batchSize := options.BatchSize
+	if batchSize <= 0 || batchSize > len(rows) {
+		batchSize = len(rows)
+	}
+	random := rand.New(rand.NewSource(options.Seed))
+
+	patience := options.Patience
+	if patience == 0 {
+		patience = defaultPatience
+	}
+	bestScore := math.Inf(-1)
+	bestWeights := make([]float64, len(weights))
+	bestEpoch := 0
+
+	finish := func(iterations int, stoppedBy string) ([]float64, Diagnostics) {
+		diagnostics := Diagnostics{Iterations: iterations, StoppedBy: stoppedBy}
+		if validation != nil {
+			diagnostics.BestIteration = bestEpoch
+			return bestWeights, diagnostics
+		}
+		return weights, diagnostics
+	}
+
+	fullBatch := batchSize == len(rows)
+	tolerance := gradientTolerance(options)
+
+	// The cost is only needed for the tolerance and the log.
+	cost := math.NaN()
+	if options.Tolerance > 0 {
+		cost = problem.cost(weights)
+	}
+	for epoch := 0; epoch < options.Iterations; epoch++ {
+		learningRate := options.Schedule.learningRate(options.LearningRate, epoch, options.Iterations)
+		if !fullBatch {
+			random.Shuffle(len(rows), func(i, j int) {
+				rows[i], rows[j] = rows[j], rows[i]
+			})
+		}
+
+		for start := 0; start < len(rows); start += batchSize {
+			batch := rows[start:min(start+batchSize, len(rows))]
+			clear(gradient)
+			weightSum := problem.gradient(weights, batch, gradient)
+			if weightSum == 0 {
+				continue
+			}
+			for j := range gradient {
+				gradient[j] /= weightSum
+			}
+			// A single batch's gradient is the full gradient at the weights
+			// of the previous epoch, so it also serves the gradient tolerance.
+			if fullBatch && epoch > 0 && floats.Norm(stationarityGradient(problem, weights, gradient), 2) <= tolerance {
+				return finish(epoch, stoppedByGradientTolerance)
+			}
+			optimizer.Step(weights, gradient, learningRate)
+			if proximal {
+				regularized.proximal(weights, learningRate)
+			}
+		}
+
+		logged := epoch%options.LogEvery == 0
+		previousCost := cost
+		if options.Tolerance > 0 || logged {
+			cost = problem.cost(weights)
+		}
+
+		var score float64
+		if validation != nil {
+			score = validationScore(validation, weights, options.Monitor)
+		}
+		if logged {
+			progress := fmt.Sprintf("  Iteration %d: Cost = %.6f", epoch, cost)
+			if !options.Schedule.isConstant() {
+				progress += fmt.Sprintf(", Learning rate = %.6g", learningRate)
+			}
+			if validation != nil {
+				progress += fmt.Sprintf(", Validation %s = %.6f", options.Monitor, math.Abs(score))
 			}
+			fmt.Fprintln(log, progress)
 		}
-		for j := range weights {
-			gradients[j] /= float64(len(y))
-			weights[j] -= alhpha * gradients[j]
+
+		if validation != nil {
+			if score > bestScore {
+				bestScore = score
+				bestEpoch = epoch + 1
+				copy(bestWeights, weights)
+			} else if epoch+1-bestEpoch >= patience {
+				return finish(epoch+1, stoppedByEarlyStopping)
+			}
 		}
 
-		if iter%100 == 0 {
-			cost := computeCost(x, y, weights)
-			fmt.Printf("  Iteration %d: Cost = %.6f\n", iter, cost)
+		if options.Tolerance > 0 && relativeChange(previousCost, cost) < options.Tolerance {
+			return finish(epoch+1, stoppedByTolerance)
+		}
+		if !fullBatch && (epoch+1)%gradientCheckEvery == 0 &&
+			floats.Norm(optimalityGradient(problem, weights), 2) <= tolerance {
+			return finish(epoch+1, stoppedByGradientTolerance)
 		}
 	}
-	return weights
-}
 
-func computeCost(x [][]float64, y []float64, weights []float64) float64 {
-	m := float64(len(y))
-	cost := 0.0
-	epsilon := 1e-15
+	return finish(options.Iterations, stoppedByMaxIterations)
+}
 
-	for i := 0; i < len(y); i++ {
-		h := predict(x[i], weights)
-		h = math.Max(epsilon, math.Min(1.0-epsilon, h))
-		cost += -y[i]*math.Log(h) - (1.0-y[i])*math.Log(1.0-h)
+// Without a weight column every sample counts once.
+func sampleWeights(dataset *hogwarts.Dataset) []float64 {
+	if dataset.Weights != nil {
+		return dataset.Weights
 	}
 
-	return cost / m
+	weights := make([]float64, len(dataset.Features))
+	for i := range weights {
+		weights[i] = 1.0
+	}
+	return weights
 }
 
 func predict(x []float64, weights []float64) float64 {
-	z := 0.0
-	for i := range len(x) {
-		z += x[i] * weights[i]
-	}
-	return sigmoid(z)
+	return sigmoid(floats.Dot(x, weights))
 }
 
 func sigmoid(z float64) float64 {
 	return 1.0 / (1.0 + math.Exp(-z))
 }
 
-func fillMissingValues(rows [][]float64, means []float64) [][]float64 {
-	x := make([][]float64, len(rows))
-	for i := range rows {
-		x[i] = make([]float64, len(rows[i]))
-		for j := range rows[i] {
-			if math.IsNaN(rows[i][j]) {
-				x[i][j] = means[j]
-			} else {
-				x[i][j] = rows[i][j]
+// designMatrix normalizes the rows, with missing values at the mean, into one
+// contiguous matrix whose first column is the bias term.
+func designMatrix(rows [][]float64, means []float64, stds []float64) *mat.Dense {
+	x := mat.NewDense(len(rows), len(means)+1, nil)
+	for i, row := range rows {
+		designRow := x.RawRowView(i)
+		designRow[0] = 1.0
+		for j, value := range row {
+			if math.IsNaN(value) {
+				value = means[j]
 			}
-		}
-	}
-
-	return x
-}
-
-func normalizeFeatures(x [][]float64, means []float64, stds []float64) {
-	for i := range x {
-		for j := range x[i] {
 			if stds[j] < 1e-10 {
-				x[i][j] = 0.0
+				designRow[j+1] = 0.0
 			} else {
-				x[i][j] = (x[i][j] - means[j]) / stds[j]
+				designRow[j+1] = (value - means[j]) / stds[j]
 			}
 		}
 	}
-}
-
-func addBiasTerm(x [][]float64) {
-	for i := range x {
-		x[i] = append([]float64{1.0}, x[i]...)
-	}
+	return x
 }
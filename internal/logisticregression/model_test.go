@@ -0,0 +1,574 @@
+package logisticregression
+
+import (
+	"dslx/internal/hogwarts"
+	"dslx/internal/stats"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+const trainingDatasetPath = "../../datasets/dataset_train.csv"
+
+func loadTrainingDataset(tb testing.TB) *hogwarts.Dataset {
+	tb.Helper()
+	dataset, err := hogwarts.LoadDatasetWithFeatures(trainingDatasetPath, true, DefaultFeatureNames)
+	if err != nil {
+		tb.Fatalf("loading %s: %v", trainingDatasetPath, err)
+	}
+	return dataset
+}
+
+// The reference functions below are the row-by-row loops over [][]float64
+// that the gonum matrices replaced.
+
+func referenceDesignMatrix(rows [][]float64, means []float64, stds []float64) [][]float64 {
+	x := make([][]float64, len(rows))
+	for i, row := range rows {
+		x[i] = make([]float64, 0, len(row)+1)
+		x[i] = append(x[i], 1.0)
+		for j, value := range row {
+			if math.IsNaN(value) {
+				value = means[j]
+			}
+			if stds[j] < 1e-10 {
+				x[i] = append(x[i], 0.0)
+			} else {
+				x[i] = append(x[i], (value-means[j])/stds[j])
+			}
+		}
+	}
+	return x
+}
+
+func referencePredict(x []float64, weights []float64) float64 {
+	z := 0.0
+	for i := range x {
+		z += x[i] * weights[i]
+	}
+	return sigmoid(z)
+}
+
+func referenceTargets(labels []string, house string) []float64 {
+	y := make([]float64, len(labels))
+	for i, label := range labels {
+		if label == house {
+			y[i] = 1.0
+		}
+	}
+	return y
+}
+
+func referenceGradient(x [][]float64, y []float64, rows []int, weights []float64) []float64 {
+	gradient := make([]float64, len(weights))
+	for _, i := range rows {
+		prediction := referencePredict(x[i], weights)
+		for j := range weights {
+			gradient[j] += (prediction - y[i]) * x[i][j]
+		}
+	}
+	for j := range gradient {
+		gradient[j] /= float64(len(rows))
+	}
+	return gradient
+}
+
+func referenceCost(x [][]float64, y []float64, weights []float64) float64 {
+	cost := 0.0
+	epsilon := 1e-15
+	for i := range y {
+		h := referencePredict(x[i], weights)
+		h = math.Max(epsilon, math.Min(1.0-epsilon, h))
+		cost += -y[i]*math.Log(h) - (1.0-y[i])*math.Log(1.0-h)
+	}
+	return cost / float64(len(y))
+}
+
+func referenceGradientDescent(x [][]float64, y []float64, learningRate float64, iterations int) []float64 {
+	rows := make([]int, len(y))
+	for i := range rows {
+		rows[i] = i
+	}
+
+	weights := make([]float64, len(x[0]))
+	for range iterations {
+		gradient := referenceGradient(x, y, rows, weights)
+		for j := range weights {
+			weights[j] -= learningRate * gradient[j]
+		}
+	}
+	return weights
+}
+
+// referenceStochasticGradientDescent takes a step per row, in the order
+// gradientDescent shuffles them into with the seed.
+func referenceStochasticGradientDescent(x [][]float64, y []float64, learningRate float64, epochs int, seed int64) []float64 {
+	rows := make([]int, len(y))
+	for i := range rows {
+		rows[i] = i
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	weights := make([]float64, len(x[0]))
+	for range epochs {
+		random.Shuffle(len(rows), func(i, j int) {
+			rows[i], rows[j] = rows[j], rows[i]
+		})
+		for _, i := range rows {
+			gradient := referenceGradient(x, y, []int{i}, weights)
+			for j := range weights {
+				weights[j] -= learningRate * gradient[j]
+			}
+		}
+	}
+	return weights
+}
+
+func referenceSoftmaxGradient(x [][]float64, labels []string, houses []string, weights [][]float64) [][]float64 {
+	gradient := make([][]float64, len(houses))
+	for k := range gradient {
+		gradient[k] = make([]float64, len(x[0]))
+	}
+	for i := range x {
+		probabilities := logits(x[i], weights)
+		softmaxInPlace(probabilities)
+		for k, house := range houses {
+			residual := probabilities[k]
+			if labels[i] == house {
+				residual -= 1.0
+			}
+			for j := range x[i] {
+				gradient[k][j] += residual * x[i][j] / float64(len(x))
+			}
+		}
+	}
+	return gradient
+}
+
+func referenceModelPredict(x [][]float64, labelNames []string, weights [][]float64) []string {
+	labels := make([]string, len(x))
+	for i := range x {
+		maxPrediction := 0.0
+		for j := range weights {
+			if prediction := referencePredict(x[i], weights[j]); prediction > maxPrediction {
+				maxPrediction = prediction
+				labels[i] = labelNames[j]
+			}
+		}
+	}
+	return labels
+}
+
+// testWeights are arbitrary weights away from the zero starting point.
+func testWeights(size int, offset float64) []float64 {
+	weights := make([]float64, size)
+	for j := range weights {
+		weights[j] = 0.3 * math.Sin(float64(j)+offset)
+	}
+	return weights
+}
+
+func assertClose(t *testing.T, name string, got []float64, want []float64, tolerance float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d values, want %d", name, len(got), len(want))
+	}
+	for j := range want {
+		if math.Abs(got[j]-want[j]) > tolerance {
+			t.Errorf("%s[%d] = %.15g, want %.15g", name, j, got[j], want[j])
+		}
+	}
+}
+
+func TestDesignMatrixMatchesRowLoops(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	means, stds := normalizationParameters(dataset)
+
+	x := designMatrix(dataset.Features, means, stds)
+	want := referenceDesignMatrix(dataset.Features, means, stds)
+
+	rows, columns := x.Dims()
+	if rows != len(want) || columns != len(want[0]) {
+		t.Fatalf("got a %dx%d matrix, want %dx%d", rows, columns, len(want), len(want[0]))
+	}
+	for i := range want {
+		assertClose(t, "row", x.RawRowView(i), want[i], 0)
+	}
+}
+
+func TestBinaryObjectiveMatchesRowLoops(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	means, stds := normalizationParameters(dataset)
+	x := designMatrix(dataset.Features, means, stds)
+	reference := referenceDesignMatrix(dataset.Features, means, stds)
+
+	allRows := make([]int, len(dataset.Labels))
+	for i := range allRows {
+		allRows[i] = i
+	}
+	batches := map[string][]int{
+		"full batch": allRows,
+		"mini-batch": {17, 3, 250, 1024, 5},
+	}
+
+	for k, house := range dataset.Houses {
+		problem := newBinaryObjective(x, dataset.Labels, house, sampleWeights(dataset))
+		y := referenceTargets(dataset.Labels, house)
+		weights := testWeights(problem.size(), float64(k))
+
+		for name, rows := range batches {
+			gradient := make([]float64, len(weights))
+			weightSum := problem.gradient(weights, rows, gradient)
+			for j := range gradient {
+				gradient[j] /= weightSum
+			}
+			assertClose(t, house+" "+name+" gradient", gradient, referenceGradient(reference, y, rows, weights), 1e-12)
+		}
+
+		got, want := problem.cost(weights), referenceCost(reference, y, weights)
+		if math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s cost = %.15g, want %.15g", house, got, want)
+		}
+	}
+}
+
+func TestSoftmaxObjectiveMatchesRowLoops(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	means, stds := normalizationParameters(dataset)
+	x := designMatrix(dataset.Features, means, stds)
+	reference := referenceDesignMatrix(dataset.Features, means, stds)
+
+	problem := newSoftmaxObjective(x, dataset.Labels, dataset.Houses, sampleWeights(dataset))
+	weights := testWeights(problem.size(), 0)
+
+	gradient := fullGradient(problem, weights)
+	want := referenceSoftmaxGradient(reference, dataset.Labels, dataset.Houses, splitWeights(weights, len(dataset.Houses)))
+	for k, house := range dataset.Houses {
+		assertClose(t, house+" gradient", splitWeights(gradient, len(dataset.Houses))[k], want[k], 1e-12)
+	}
+}
+
+func TestTrainMatchesRowLoops(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	const learningRate, iterations = 0.1, 300
+
+	model, err := TrainNewModelWithOptions(dataset, TrainOptions{
+		LearningRate: learningRate,
+		Iterations:   iterations,
+	})
+	if err != nil {
+		t.Fatalf("training: %v", err)
+	}
+
+	means, stds := normalizationParameters(dataset)
+	reference := referenceDesignMatrix(dataset.Features, means, stds)
+	for k, house := range dataset.Houses {
+		if model.Diagnostics[k].Iterations != iterations {
+			t.Errorf("%s trained for %d iterations, want %d", house, model.Diagnostics[k].Iterations, iterations)
+		}
+		want := referenceGradientDescent(reference, referenceTargets(dataset.Labels, house), learningRate, iterations)
+		assertClose(t, house+" weights", model.Weights[k], want, 1e-9)
+	}
+
+	predictions := model.Predict(dataset)
+	want := referenceModelPredict(reference, model.LabelNames, model.Weights)
+	for i := range want {
+		if predictions[i] != want[i] {
+			t.Errorf("row %d predicted %q, want %q", i, predictions[i], want[i])
+		}
+	}
+}
+
+func TestTrainWithSeedIsReproducible(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	train := func(seed int64) *Model {
+		model, err := TrainNewModelWithOptions(dataset, TrainOptions{
+			LearningRate: 0.05,
+			Iterations:   3,
+			BatchSize:    32,
+			Seed:         seed,
+			Optimizer:    DefaultOptimizerConfig(Adam),
+		})
+		if err != nil {
+			t.Fatalf("training: %v", err)
+		}
+		return model
+	}
+
+	first, second := train(3), train(3)
+	for k, house := range first.LabelNames {
+		assertClose(t, house+" weights", second.Weights[k], first.Weights[k], 0)
+	}
+	if other := train(4); reflect.DeepEqual(other.Weights, first.Weights) {
+		t.Error("seeds 3 and 4 gave the same weights")
+	}
+}
+
+// A batch of every row, or more, is full-batch gradient descent.
+func TestTrainBatchOfEveryRowIsFullBatch(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	train := func(batchSize int) *Model {
+		model, err := TrainNewModelWithOptions(dataset, TrainOptions{
+			LearningRate: 0.1,
+			Iterations:   50,
+			BatchSize:    batchSize,
+			Seed:         5,
+		})
+		if err != nil {
+			t.Fatalf("training: %v", err)
+		}
+		return model
+	}
+
+	fullBatch := train(0)
+	for _, batchSize := range []int{len(dataset.Features), len(dataset.Features) + 10} {
+		model := train(batchSize)
+		for k, house := range fullBatch.LabelNames {
+			assertClose(t, house+" weights", model.Weights[k], fullBatch.Weights[k], 0)
+		}
+	}
+}
+
+func TestTrainStochasticMatchesRowLoops(t *testing.T) {
+	rows := make([]int, 120)
+	for i := range rows {
+		rows[i] = 7 * i
+	}
+	dataset := loadTrainingDataset(t).Subset(rows)
+	const learningRate, epochs, seed = 0.05, 3, 11
+
+	model, err := TrainNewModelWithOptions(dataset, TrainOptions{
+		LearningRate: learningRate,
+		Iterations:   epochs,
+		BatchSize:    1,
+		Seed:         seed,
+	})
+	if err != nil {
+		t.Fatalf("training: %v", err)
+	}
+
+	means, stds := normalizationParameters(dataset)
+	reference := referenceDesignMatrix(dataset.Features, means, stds)
+	for k, house := range dataset.Houses {
+		want := referenceStochasticGradientDescent(reference, referenceTargets(dataset.Labels, house), learningRate, epochs, seed)
+		assertClose(t, house+" weights", model.Weights[k], want, 1e-12)
+	}
+}
+
+func TestPredictProbaRowsSumToOne(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	for _, strategy := range []Strategy{OneVsRest, Softmax} {
+		model, err := TrainNewModelWithOptions(dataset, TrainOptions{LearningRate: 0.1, Iterations: 100, Strategy: strategy})
+		if err != nil {
+			t.Fatalf("%s: training: %v", strategy, err)
+		}
+
+		probabilities := model.PredictProba(dataset)
+		rows, columns := probabilities.Dims()
+		if rows != len(dataset.Features) || columns != len(model.LabelNames) {
+			t.Fatalf("%s: got a %dx%d matrix, want %dx%d", strategy, rows, columns, len(dataset.Features), len(model.LabelNames))
+		}
+		predictions := model.Predict(dataset)
+		for i := 0; i < rows; i++ {
+			row := probabilities.RawRowView(i)
+			sum, best := 0.0, 0
+			for j, probability := range row {
+				if probability < 0 || probability > 1 {
+					t.Fatalf("%s: row %d has probability %g", strategy, i, probability)
+				}
+				sum += probability
+				if probability > row[best] {
+					best = j
+				}
+			}
+			if math.Abs(sum-1) > 1e-12 {
+				t.Errorf("%s: row %d sums to %.15g", strategy, i, sum)
+			}
+			if model.LabelNames[best] != predictions[i] {
+				t.Errorf("%s: row %d is most likely %q, Predict gives %q", strategy, i, model.LabelNames[best], predictions[i])
+			}
+		}
+	}
+}
+
+func TestPredictTopK(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	model, err := TrainNewModelWithOptions(dataset, TrainOptions{LearningRate: 0.1, Iterations: 100})
+	if err != nil {
+		t.Fatalf("training: %v", err)
+	}
+	probabilities := model.PredictProba(dataset)
+	predictions := model.Predict(dataset)
+
+	for _, k := range []int{1, 2, len(model.LabelNames), len(model.LabelNames) + 3} {
+		topK, err := model.PredictTopK(dataset, k)
+		if err != nil {
+			t.Fatalf("k=%d: unexpected error: %v", k, err)
+		}
+		want := min(k, len(model.LabelNames))
+		for i, ranked := range topK {
+			if len(ranked) != want {
+				t.Fatalf("k=%d: row %d has %d predictions, want %d", k, i, len(ranked), want)
+			}
+			if ranked[0].Label != predictions[i] {
+				t.Errorf("k=%d: row %d ranks %q first, Predict gives %q", k, i, ranked[0].Label, predictions[i])
+			}
+			for j, prediction := range ranked {
+				if j > 0 && prediction.Probability > ranked[j-1].Probability {
+					t.Errorf("k=%d: row %d is not ordered at %d", k, i, j)
+				}
+				column := slices.Index(model.LabelNames, prediction.Label)
+				if prediction.Probability != probabilities.At(i, column) {
+					t.Errorf("k=%d: row %d gives %q probability %g, PredictProba %g", k, i, prediction.Label, prediction.Probability, probabilities.At(i, column))
+				}
+			}
+		}
+	}
+
+	for _, k := range []int{0, -1} {
+		if _, err := model.PredictTopK(dataset, k); err == nil {
+			t.Errorf("k=%d: expected an error", k)
+		}
+	}
+}
+
+// The training rows are separable, so their weights grow without bound, while
+// half the validation rows are mislabelled and prefer a moderate slope. The
+// validation loss therefore improves for a while and then worsens.
+func TestGradientDescentStopsEarlyAndRestoresBestWeights(t *testing.T) {
+	training := newBinaryObjective(
+		designMatrix([][]float64{{-2}, {-1}, {1}, {2}}, []float64{0}, []float64{1}),
+		[]string{"No", "No", "Yes", "Yes"}, "Yes", []float64{1, 1, 1, 1})
+	validation := newBinaryObjective(
+		designMatrix([][]float64{{-1}, {1}, {0.5}, {-0.5}}, []float64{0}, []float64{1}),
+		[]string{"No", "Yes", "No", "Yes"}, "Yes", []float64{1, 1, 1, 1})
+	options := TrainOptions{
+		LearningRate: 0.1,
+		Iterations:   500,
+		BatchSize:    2,
+		Seed:         7,
+		Patience:     5,
+		LogEvery:     defaultLogEvery,
+	}
+
+	weights, diagnostics := gradientDescent(training, validation, options, io.Discard)
+	if diagnostics.StoppedBy != stoppedByEarlyStopping {
+		t.Fatalf("stopped by %q, want %q", diagnostics.StoppedBy, stoppedByEarlyStopping)
+	}
+	if diagnostics.BestIteration < 5 {
+		t.Errorf("best epoch %d, want the validation loss to improve first", diagnostics.BestIteration)
+	}
+	if diagnostics.Iterations != diagnostics.BestIteration+options.Patience {
+		t.Errorf("stopped after %d epochs, want %d", diagnostics.Iterations, diagnostics.BestIteration+options.Patience)
+	}
+
+	// Training for the best number of epochs with the same seed shuffles
+	// the same way and reaches the restored weights.
+	options.Iterations = diagnostics.BestIteration
+	want, _ := gradientDescent(training, nil, options, io.Discard)
+	assertClose(t, "weights", weights, want, 0)
+
+	bestLoss := validation.cost(weights)
+	options.Iterations++
+	later, _ := gradientDescent(training, nil, options, io.Discard)
+	if loss := validation.cost(later); loss <= bestLoss {
+		t.Errorf("validation loss %g after the best epoch, want above %g", loss, bestLoss)
+	}
+}
+
+// BenchmarkTrain trains the classifiers one after another, as
+// BenchmarkTrainRowLoops does.
+func BenchmarkTrain(b *testing.B) {
+	dataset := loadTrainingDataset(b)
+	b.ResetTimer()
+	for range b.N {
+		options := TrainOptions{LearningRate: 0.01, Iterations: 1000, Workers: 1}
+		if _, err := TrainNewModelWithOptions(dataset, options); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTrainSoftmax(b *testing.B) {
+	dataset := loadTrainingDataset(b)
+	b.ResetTimer()
+	for range b.N {
+		options := TrainOptions{LearningRate: 0.01, Iterations: 1000, Strategy: Softmax}
+		if _, err := TrainNewModelWithOptions(dataset, options); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkTrainRowLoops trains like BenchmarkTrain with the loops over
+// [][]float64, for comparison.
+func BenchmarkTrainRowLoops(b *testing.B) {
+	dataset := loadTrainingDataset(b)
+	b.ResetTimer()
+	for range b.N {
+		means, stds := normalizationParameters(dataset)
+		x := referenceDesignMatrix(dataset.Features, means, stds)
+		for _, house := range dataset.Houses {
+			referenceGradientDescent(x, referenceTargets(dataset.Labels, house), 0.01, 1000)
+		}
+	}
+}
+
+func BenchmarkPredict(b *testing.B) {
+	dataset := loadTrainingDataset(b)
+	model, err := TrainNewModelWithOptions(dataset, TrainOptions{LearningRate: 0.1, Iterations: 100})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for range b.N {
+		model.Predict(dataset)
+	}
+}
+
+// BenchmarkPredictRowLoops predicts like BenchmarkPredict with the loops over
+// [][]float64, for comparison. Both spend most of their time in the sigmoid of
+// the few scores per row, so the matrix product gains little here.
+func BenchmarkPredictRowLoops(b *testing.B) {
+	dataset := loadTrainingDataset(b)
+	model, err := TrainNewModelWithOptions(dataset, TrainOptions{LearningRate: 0.1, Iterations: 100})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for range b.N {
+		x := referenceDesignMatrix(dataset.Features, model.Means, model.Stds)
+		referenceModelPredict(x, model.LabelNames, model.Weights)
+	}
+}
+
+// The model standardizes with the dataset's ddof and records it.
+func TestTrainRecordsStdDdof(t *testing.T) {
+	for _, ddof := range []int{0, 1} {
+		dataset, err := hogwarts.LoadDatasetWithOptions(trainingDatasetPath, hogwarts.LoadOptions{
+			SkipEmptyHouses: true,
+			Features:        DefaultFeatureNames,
+			Ddof:            ddof,
+		})
+		if err != nil {
+			t.Fatalf("loading %s: %v", trainingDatasetPath, err)
+		}
+		model, err := TrainNewModelWithOptions(dataset, TrainOptions{LearningRate: 0.1, Iterations: 1})
+		if err != nil {
+			t.Fatalf("ddof %d: training: %v", ddof, err)
+		}
+
+		if model.StdDdof != ddof {
+			t.Errorf("ddof %d: model records ddof %d", ddof, model.StdDdof)
+		}
+		for j, name := range model.FeatureNames {
+			want := stats.StdWithDdof(dataset.GetFeatureValues(j), ddof)
+			if math.Abs(model.Stds[j]-want) > 1e-12*want {
+				t.Errorf("ddof %d: std of %s is %g, want %g", ddof, name, model.Stds[j], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package logisticregression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// objective is a weighted training loss over flat weight vectors, so that the
+// binary and softmax problems share one training loop. The design matrix x
+// holds one normalized row per sample, with the bias term first.
+type objective interface {
+	size() int
+	rowCount() int
+	// gradient adds the loss gradient over rows, unnormalized, and returns
+	// the total sample weight of those rows.
+	gradient(weights []float64, rows []int, gradient []float64) float64
+	cost(weights []float64) float64
+	// accuracy is the weighted share of rows predicted correctly.
+	accuracy(weights []float64) float64
+	// hessian is the second derivative of cost over all rows.
+	hessian(weights []float64) *mat.SymDense
+}
+
+type binaryObjective struct {
+	x             *mat.Dense
+	y             []float64
+	sampleWeights []float64
+}
+
+// newBinaryObjective separates the rows labelled house from the others.
+func newBinaryObjective(x *mat.Dense, labels []string, house string, sampleWeights []float64) *binaryObjective {
+	y := make([]float64, 0, len(labels))
+	for _, label := range labels {
+		if label == house {
+			y = append(y, 1.0)
+		} else {
+			y = append(y, 0.0)
+		}
+	}
+	return &binaryObjective{x: x, y: y, sampleWeights: sampleWeights}
+}
+
+func (o *binaryObjective) size() int {
+	_, columns := o.x.Dims()
+	return columns
+}
+
+func (o *binaryObjective) rowCount() int {
+	rows, _ := o.x.Dims()
+	return rows
+}
+
+func (o *binaryObjective) gradient(weights []float64, rows []int, gradient []float64) float64 {
+	weightSum := 0.0
+	if !isFullBatch(rows, o.rowCount()) {
+		for _, i := range rows {
+			row := o.x.RawRowView(i)
+			residual := o.sampleWeights[i] * (predict(row, weights) - o.y[i])
+			floats.AddScaled(gradient, residual, row)
+			weightSum += o.sampleWeights[i]
+		}
+		return weightSum
+	}
+
+	residuals := mulVec(o.x, weights)
+	for i := range residuals {
+		residuals[i] = o.sampleWeights[i] * (sigmoid(residuals[i]) - o.y[i])
+		weightSum += o.sampleWeights[i]
+	}
+	floats.Add(gradient, mulVec(o.x.T(), residuals))
+	return weightSum
+}
+
+func (o *binaryObjective) cost(weights []float64) float64 {
+	weightSum := 0.0
+	cost := 0.0
+	epsilon := 1e-15
+
+	for i, z := range mulVec(o.x, weights) {
+		h := math.Max(epsilon, math.Min(1.0-epsilon, sigmoid(z)))
+		cost += o.sampleWeights[i] * (-o.y[i]*math.Log(h) - (1.0-o.y[i])*math.Log(1.0-h))
+		weightSum += o.sampleWeights[i]
+	}
+
+	return cost / weightSum
+}
+
+func (o *binaryObjective) accuracy(weights []float64) float64 {
+	weightSum := 0.0
+	correct := 0.0
+	for i, z := range mulVec(o.x, weights) {
+		if (sigmoid(z) >= 0.5) == (o.y[i] == 1.0) {
+			correct += o.sampleWeights[i]
+		}
+		weightSum += o.sampleWeights[i]
+	}
+	return correct / weightSum
+}
+
+// hessian is XᵀDX/Σw with D the diagonal of w·p·(1-p), computed as SᵀS for
+// the rows of X scaled by the square root of D.
+func (o *binaryObjective) hessian(weights []float64) *mat.SymDense {
+	rows, columns := o.x.Dims()
+	scaled := mat.NewDense(rows, columns, nil)
+	weightSum := 0.0
+	for i, z := range mulVec(o.x, weights) {
+		prediction := sigmoid(z)
+		floats.ScaleTo(scaled.RawRowView(i), math.Sqrt(o.sampleWeights[i]*prediction*(1-prediction)), o.x.RawRowView(i))
+		weightSum += o.sampleWeights[i]
+	}
+
+	hessian := mat.NewSymDense(columns, nil)
+	hessian.SymOuterK(1/weightSum, scaled.T())
+	return hessian
+}
+
+// isFullBatch reports whether rows are all rows in order, which the
+// objectives compute with matrix products over the whole design matrix.
+// Smaller batches work row by row, which avoids copying their rows.
+func isFullBatch(rows []int, rowCount int) bool {
+	if len(rows) != rowCount {
+		return false
+	}
+	for b, i := range rows {
+		if b != i {
+			return false
+		}
+	}
+	return true
+}
+
+func mulVec(a mat.Matrix, values []float64) []float64 {
+	rows, _ := a.Dims()
+	var result mat.VecDense
+	result.MulVec(a, mat.NewVecDense(len(values), values))
+	return result.RawVector().Data[:rows]
+}
@@ -0,0 +1,281 @@
+package logisticregression
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Optimizer turns the gradient of a batch into a weight update. Optimizers
+// keep per-weight state, so each training problem needs its own.
+type Optimizer interface {
+	Step(weights []float64, gradient []float64, learningRate float64)
+}
+
+type OptimizerKind int
+
+const (
+	SGD OptimizerKind = iota
+	Momentum
+	Nesterov
+	AdaGrad
+	RMSProp
+	Adam
+)
+
+func ParseOptimizerKind(name string) (OptimizerKind, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "sgd":
+		return SGD, nil
+	case "momentum":
+		return Momentum, nil
+	case "nesterov":
+		return Nesterov, nil
+	case "adagrad":
+		return AdaGrad, nil
+	case "rmsprop":
+		return RMSProp, nil
+	case "adam":
+		return Adam, nil
+	}
+	return SGD, fmt.Errorf("unknown optimizer %q", name)
+}
+
+func (k OptimizerKind) String() string {
+	switch k {
+	case SGD:
+		return "sgd"
+	case Momentum:
+		return "momentum"
+	case Nesterov:
+		return "nesterov"
+	case AdaGrad:
+		return "adagrad"
+	case RMSProp:
+		return "rmsprop"
+	case Adam:
+		return "adam"
+	}
+	return fmt.Sprintf("OptimizerKind(%d)", int(k))
+}
+
+func (k OptimizerKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *OptimizerKind) UnmarshalText(text []byte) error {
+	kind, err := ParseOptimizerKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+// OptimizerConfig holds the hyperparameters of every optimizer. Only the ones
+// used by Kind are kept, and zero is a valid setting for each of them apart
+// from Epsilon; DefaultOptimizerConfig gives the usual values.
+type OptimizerConfig struct {
+	Kind OptimizerKind `json:"kind"`
+	// Momentum is the velocity decay of Momentum and Nesterov.
+	Momentum float64 `json:"momentum,omitempty"`
+	// Decay is the decay of the squared gradient average of RMSProp.
+	Decay float64 `json:"decay,omitempty"`
+	Beta1 float64 `json:"beta1,omitempty"`
+	Beta2 float64 `json:"beta2,omitempty"`
+	// Epsilon keeps the adaptive optimizers from dividing by zero.
+	Epsilon float64 `json:"epsilon,omitempty"`
+}
+
+// DefaultOptimizerConfig returns the usual hyperparameters of kind.
+func DefaultOptimizerConfig(kind OptimizerKind) OptimizerConfig {
+	return OptimizerConfig{
+		Kind:     kind,
+		Momentum: 0.9,
+		Decay:    0.9,
+		Beta1:    0.9,
+		Beta2:    0.999,
+		Epsilon:  1e-8,
+	}.normalized()
+}
+
+func (c OptimizerConfig) normalized() OptimizerConfig {
+	normalized := OptimizerConfig{Kind: c.Kind}
+	switch c.Kind {
+	case Momentum, Nesterov:
+		normalized.Momentum = c.Momentum
+	case AdaGrad:
+		normalized.Epsilon = c.Epsilon
+	case RMSProp:
+		normalized.Decay = c.Decay
+		normalized.Epsilon = c.Epsilon
+	case Adam:
+		normalized.Beta1 = c.Beta1
+		normalized.Beta2 = c.Beta2
+		normalized.Epsilon = c.Epsilon
+	}
+	return normalized
+}
+
+// MarshalJSON writes every hyperparameter used by Kind, zero or not, so a
+// recorded zero is not mistaken for an unset value.
+func (c OptimizerConfig) MarshalJSON() ([]byte, error) {
+	c = c.normalized()
+	record := struct {
+		Kind     OptimizerKind `json:"kind"`
+		Momentum *float64      `json:"momentum,omitempty"`
+		Decay    *float64      `json:"decay,omitempty"`
+		Beta1    *float64      `json:"beta1,omitempty"`
+		Beta2    *float64      `json:"beta2,omitempty"`
+		Epsilon  *float64      `json:"epsilon,omitempty"`
+	}{Kind: c.Kind}
+	switch c.Kind {
+	case Momentum, Nesterov:
+		record.Momentum = &c.Momentum
+	case AdaGrad:
+		record.Epsilon = &c.Epsilon
+	case RMSProp:
+		record.Decay = &c.Decay
+		record.Epsilon = &c.Epsilon
+	case Adam:
+		record.Beta1 = &c.Beta1
+		record.Beta2 = &c.Beta2
+		record.Epsilon = &c.Epsilon
+	}
+	return json.Marshal(record)
+}
+
+func (c OptimizerConfig) validate() error {
+	inUnitInterval := func(value float64) bool {
+		return value >= 0 && value < 1
+	}
+
+	if c.Kind < SGD || c.Kind > Adam {
+		return fmt.Errorf("unknown optimizer %v", c.Kind)
+	}
+	if !inUnitInterval(c.Momentum) {
+		return errors.New("momentum must be in [0, 1)")
+	}
+	if !inUnitInterval(c.Decay) {
+		return errors.New("decay must be in [0, 1)")
+	}
+	if !inUnitInterval(c.Beta1) || !inUnitInterval(c.Beta2) {
+		return errors.New("beta1 and beta2 must be in [0, 1)")
+	}
+	if c.Epsilon < 0 {
+		return errors.New("epsilon must not be negative")
+	}
+	if (c.Kind == AdaGrad || c.Kind == RMSProp || c.Kind == Adam) && c.Epsilon == 0 {
+		return fmt.Errorf("epsilon of %v must be positive", c.Kind)
+	}
+	return nil
+}
+
+// NewOptimizer returns a fresh optimizer for size weights.
+func NewOptimizer(config OptimizerConfig, size int) Optimizer {
+	config = config.normalized()
+	switch config.Kind {
+	case Momentum, Nesterov:
+		return &momentumOptimizer{
+			momentum: config.Momentum,
+			nesterov: config.Kind == Nesterov,
+			velocity: make([]float64, size),
+		}
+	case AdaGrad:
+		return &adaGradOptimizer{
+			epsilon:    config.Epsilon,
+			sumSquares: make([]float64, size),
+		}
+	case RMSProp:
+		return &rmsPropOptimizer{
+			decay:       config.Decay,
+			epsilon:     config.Epsilon,
+			meanSquares: make([]float64, size),
+		}
+	case Adam:
+		return &adamOptimizer{
+			beta1:       config.Beta1,
+			beta2:       config.Beta2,
+			epsilon:     config.Epsilon,
+			moments:     make([]float64, size),
+			meanSquares: make([]float64, size),
+		}
+	}
+	return sgdOptimizer{}
+}
+
+type sgdOptimizer struct{}
+
+func (sgdOptimizer) Step(weights []float64, gradient []float64, learningRate float64) {
+	for j := range weights {
+		weights[j] -= learningRate * gradient[j]
+	}
+}
+
+// momentumOptimizer follows the formulation where Nesterov's look-ahead is
+// folded into the update, so no gradient at shifted weights is needed.
+type momentumOptimizer struct {
+	momentum float64
+	nesterov bool
+	velocity []float64
+}
+
+func (o *momentumOptimizer) Step(weights []float64, gradient []float64, learningRate float64) {
+	for j := range weights {
+		o.velocity[j] = o.momentum*o.velocity[j] + gradient[j]
+		direction := o.velocity[j]
+		if o.nesterov {
+			direction = gradient[j] + o.momentum*o.velocity[j]
+		}
+		weights[j] -= learningRate * direction
+	}
+}
+
+type adaGradOptimizer struct {
+	epsilon    float64
+	sumSquares []float64
+}
+
+func (o *adaGradOptimizer) Step(weights []float64, gradient []float64, learningRate float64) {
+	for j := range weights {
+		o.sumSquares[j] += gradient[j] * gradient[j]
+		weights[j] -= learningRate * gradient[j] / (math.Sqrt(o.sumSquares[j]) + o.epsilon)
+	}
+}
+
+type rmsPropOptimizer struct {
+	decay       float64
+	epsilon     float64
+	meanSquares []float64
+}
+
+func (o *rmsPropOptimizer) Step(weights []float64, gradient []float64, learningRate float64) {
+	for j := range weights {
+		o.meanSquares[j] = o.decay*o.meanSquares[j] + (1-o.decay)*gradient[j]*gradient[j]
+		weights[j] -= learningRate * gradient[j] / (math.Sqrt(o.meanSquares[j]) + o.epsilon)
+	}
+}
+
+type adamOptimizer struct {
+	beta1       float64
+	beta2       float64
+	epsilon     float64
+	moments     []float64
+	meanSquares []float64
+	steps       int
+}
+
+func (o *adamOptimizer) Step(weights []float64, gradient []float64, learningRate float64) {
+	o.steps++
+	momentCorrection := 1 - math.Pow(o.beta1, float64(o.steps))
+	meanSquareCorrection := 1 - math.Pow(o.beta2, float64(o.steps))
+	for j := range weights {
+		o.moments[j] = o.beta1*o.moments[j] + (1-o.beta1)*gradient[j]
+		o.meanSquares[j] = o.beta2*o.meanSquares[j] + (1-o.beta2)*gradient[j]*gradient[j]
+		moment := o.moments[j] / momentCorrection
+		meanSquare := o.meanSquares[j] / meanSquareCorrection
+		weights[j] -= learningRate * moment / (math.Sqrt(meanSquare) + o.epsilon)
+	}
+}
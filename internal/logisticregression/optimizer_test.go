@@ -0,0 +1,99 @@
+package logisticregression
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Every case takes the steps with gradients [0.5, -0.25] then [0.1, 0.2] from
+// weights [1, -2] at learning rate 0.1.
+func TestOptimizerSteps(t *testing.T) {
+	withMomentum := func(kind OptimizerKind, momentum float64) OptimizerConfig {
+		config := DefaultOptimizerConfig(kind)
+		config.Momentum = momentum
+		return config
+	}
+	rmsProp := func(decay float64) OptimizerConfig {
+		config := DefaultOptimizerConfig(RMSProp)
+		config.Decay = decay
+		return config
+	}
+	adam := func(beta1 float64) OptimizerConfig {
+		config := DefaultOptimizerConfig(Adam)
+		config.Beta1 = beta1
+		return config
+	}
+
+	tests := []struct {
+		name   string
+		config OptimizerConfig
+		want   []float64
+	}{
+		{"sgd", OptimizerConfig{}, []float64{0.94, -1.995}},
+		{"momentum", withMomentum(Momentum, 0.9), []float64{0.895, -1.9725}},
+		{"momentum 0 is sgd", withMomentum(Momentum, 0), []float64{0.94, -1.995}},
+		{"nesterov", withMomentum(Nesterov, 0.9), []float64{0.8455, -1.97025}},
+		{"nesterov 0 is sgd", withMomentum(Nesterov, 0), []float64{0.94, -1.995}},
+		{"adagrad", DefaultOptimizerConfig(AdaGrad), []float64{0.880388388870797, -1.9624695068042228}},
+		{"rmsprop", rmsProp(0.9), []float64{0.6185394509331354, -1.8876311297789885}},
+		// Without decay every step has the length of the learning rate.
+		{"rmsprop decay 0", rmsProp(0), []float64{0.8000000119999989, -1.9999999990000001}},
+		{"adam", adam(0.9), []float64{0.8196959063846518, -1.8941874995266317}},
+		{"adam beta1 0", adam(0), []float64{0.8722585871510615, -1.9883500719951948}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.config.validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			weights := []float64{1, -2}
+			optimizer := NewOptimizer(test.config, len(weights))
+			optimizer.Step(weights, []float64{0.5, -0.25}, 0.1)
+			optimizer.Step(weights, []float64{0.1, 0.2}, 0.1)
+			assertClose(t, "weights", weights, test.want, 1e-12)
+		})
+	}
+}
+
+func TestOptimizerConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  OptimizerConfig
+		wantErr bool
+	}{
+		{"unset is sgd", OptimizerConfig{}, false},
+		{"zero momentum", OptimizerConfig{Kind: Momentum}, false},
+		{"adam without epsilon", OptimizerConfig{Kind: Adam, Beta1: 0.9, Beta2: 0.999}, true},
+		{"momentum of one", OptimizerConfig{Kind: Momentum, Momentum: 1}, true},
+		{"negative decay", OptimizerConfig{Kind: RMSProp, Decay: -0.1, Epsilon: 1e-8}, true},
+	}
+	for _, test := range tests {
+		if err := test.config.validate(); (err != nil) != test.wantErr {
+			t.Errorf("%s: got error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestOptimizerConfigRecordsZeroHyperparameters(t *testing.T) {
+	config := DefaultOptimizerConfig(Adam)
+	config.Beta1 = 0
+	config.Momentum = 0.5
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"kind":"adam","beta1":0,"beta2":0.999,"epsilon":1e-8}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	var decoded OptimizerConfig
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded != config.normalized() {
+		t.Errorf("decoded %+v, want %+v", decoded, config.normalized())
+	}
+}
@@ -0,0 +1,190 @@
+package logisticregression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type RegularizationType int
+
+const (
+	NoRegularization RegularizationType = iota
+	L2
+	L1
+	ElasticNet
+)
+
+func ParseRegularizationType(name string) (RegularizationType, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "none":
+		return NoRegularization, nil
+	case "l2", "ridge":
+		return L2, nil
+	case "l1", "lasso":
+		return L1, nil
+	case "elasticnet", "elastic-net":
+		return ElasticNet, nil
+	}
+	return NoRegularization, fmt.Errorf("unknown regularization %q", name)
+}
+
+func (t RegularizationType) String() string {
+	switch t {
+	case NoRegularization:
+		return "none"
+	case L2:
+		return "l2"
+	case L1:
+		return "l1"
+	case ElasticNet:
+		return "elasticnet"
+	}
+	return fmt.Sprintf("RegularizationType(%d)", int(t))
+}
+
+func (t RegularizationType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *RegularizationType) UnmarshalText(text []byte) error {
+	regularizationType, err := ParseRegularizationType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = regularizationType
+	return nil
+}
+
+// Regularization adds Lambda * (L1Ratio * |w|₁ + (1 - L1Ratio) / 2 * |w|²)
+// to the cost, leaving the bias weights out. L1Ratio only applies to
+// ElasticNet; L2 and L1 fix it at 0 and 1.
+type Regularization struct {
+	Type    RegularizationType `json:"type"`
+	Lambda  float64            `json:"lambda"`
+	L1Ratio float64            `json:"l1_ratio,omitempty"`
+}
+
+func (r Regularization) validate() error {
+	if r.Type < NoRegularization || r.Type > ElasticNet {
+		return fmt.Errorf("unknown regularization %v", r.Type)
+	}
+	if r.Lambda < 0 {
+		return errors.New("regularization strength must not be negative")
+	}
+	if r.Type == ElasticNet && (r.L1Ratio < 0 || r.L1Ratio > 1) {
+		return errors.New("l1 ratio must be in [0, 1]")
+	}
+	return nil
+}
+
+func (r Regularization) strengths() (l1 float64, l2 float64) {
+	switch r.Type {
+	case L2:
+		return 0, r.Lambda
+	case L1:
+		return r.Lambda, 0
+	case ElasticNet:
+		return r.Lambda * r.L1Ratio, r.Lambda * (1 - r.L1Ratio)
+	}
+	return 0, 0
+}
+
+// regularizedObjective adds the penalty to an objective. The L2 part is
+// smooth and enters the cost, gradient and Hessian; the L1 part only enters
+// the cost and is applied by proximal steps.
+type regularizedObjective struct {
+	objective
+	l1 float64
+	l2 float64
+	// blockSize is the number of weights per class, the first of which is
+	// the bias.
+	blockSize int
+}
+
+func regularize(problem objective, regularization Regularization, blockSize int) objective {
+	l1, l2 := regularization.strengths()
+	if l1 == 0 && l2 == 0 {
+		return problem
+	}
+	return &regularizedObjective{objective: problem, l1: l1, l2: l2, blockSize: blockSize}
+}
+
+func (o *regularizedObjective) isBias(j int) bool {
+	return j%o.blockSize == 0
+}
+
+// gradient scales the penalty by the weight of the rows, since callers
+// divide the result by it.
+func (o *regularizedObjective) gradient(weights []float64, rows []int, gradient []float64) float64 {
+	weightSum := o.objective.gradient(weights, rows, gradient)
+	for j := range weights {
+		if !o.isBias(j) {
+			gradient[j] += weightSum * o.l2 * weights[j]
+		}
+	}
+	return weightSum
+}
+
+func (o *regularizedObjective) cost(weights []float64) float64 {
+	cost := o.objective.cost(weights)
+	for j := range weights {
+		if !o.isBias(j) {
+			cost += o.l1*math.Abs(weights[j]) + o.l2/2*weights[j]*weights[j]
+		}
+	}
+	return cost
+}
+
+func (o *regularizedObjective) hessian(weights []float64) *mat.SymDense {
+	hessian := o.objective.hessian(weights)
+	for j := range weights {
+		if !o.isBias(j) {
+			hessian.SetSym(j, j, hessian.At(j, j)+o.l2)
+		}
+	}
+	return hessian
+}
+
+// proximal soft-thresholds the weights after a gradient step of the given
+// size, which sets the weights of unhelpful features to exactly zero.
+func (o *regularizedObjective) proximal(weights []float64, step float64) {
+	threshold := step * o.l1
+	for j := range weights {
+		if !o.isBias(j) {
+			weights[j] = softThreshold(weights[j], threshold)
+		}
+	}
+}
+
+// stationarity is the smallest subgradient of the cost, which is zero
+// exactly at the optimum even where the L1 penalty has a kink.
+func (o *regularizedObjective) stationarity(weights []float64, gradient []float64) []float64 {
+	result := make([]float64, len(gradient))
+	for j := range gradient {
+		switch {
+		case o.isBias(j):
+			result[j] = gradient[j]
+		case weights[j] > 0:
+			result[j] = gradient[j] + o.l1
+		case weights[j] < 0:
+			result[j] = gradient[j] - o.l1
+		default:
+			result[j] = softThreshold(gradient[j], o.l1)
+		}
+	}
+	return result
+}
+
+func softThreshold(value float64, threshold float64) float64 {
+	switch {
+	case value > threshold:
+		return value - threshold
+	case value < -threshold:
+		return value + threshold
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package logisticregression
+
+import "testing"
+
+// Each class has three weights, the first of which is its bias. The
+// threshold of the step is 0.25 * 1.
+func TestProximalSoftThresholdsAllButTheBias(t *testing.T) {
+	problem := &regularizedObjective{l1: 1, blockSize: 3}
+	weights := []float64{5, 0.125, -0.75, -0.125, -0.25, 0.5}
+
+	problem.proximal(weights, 0.25)
+	assertClose(t, "weights", weights, []float64{5, 0, -0.5, -0.125, 0, 0.25}, 0)
+}
+
+// A penalty stronger than any gradient of the loss leaves only the biases.
+func TestL1TrainingZeroesEveryFeatureWeight(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	model, err := TrainNewModelWithOptions(dataset, TrainOptions{
+		LearningRate:   0.1,
+		Iterations:     200,
+		Regularization: Regularization{Type: L1, Lambda: 10},
+	})
+	if err != nil {
+		t.Fatalf("training: %v", err)
+	}
+
+	for k, house := range model.LabelNames {
+		if model.Weights[k][0] == 0 {
+			t.Errorf("%s: bias is zero", house)
+		}
+		for j, weight := range model.Weights[k][1:] {
+			if weight != 0 {
+				t.Errorf("%s: weight of %s is %g, want 0", house, model.FeatureNames[j], weight)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package logisticregression
+
+import (
+	"dslx/internal/hogwarts"
+	"fmt"
+	"math"
+)
+
+// DefaultUnknownLabel is the label given to rejected rows unless
+// RejectOptions names another.
+const DefaultUnknownLabel = "Unknown"
+
+// RejectReason is the code explaining why a row was not given a label.
+type RejectReason string
+
+const (
+	NotRejected RejectReason = ""
+	// RejectedNoProbability marks rows where no class has a positive
+	// probability, such as when every one-vs-rest sigmoid underflows.
+	RejectedNoProbability  RejectReason = "no-probability"
+	RejectedLowProbability RejectReason = "low-probability"
+	RejectedLowMargin      RejectReason = "low-margin"
+)
+
+// RejectOptions sets when a prediction is rejected. A row is rejected when
+// its most probable class is below MinProbability, or leads the runner-up by
+// less than MinMargin. The zero value only rejects rows without any
+// probability.
+type RejectOptions struct {
+	MinProbability float64
+	MinMargin      float64
+	UnknownLabel   string
+}
+
+func (o RejectOptions) validate() error {
+	if o.MinProbability < 0 || o.MinProbability > 1 {
+		return fmt.Errorf("minimum probability must be between 0 and 1, got %g", o.MinProbability)
+	}
+	if o.MinMargin < 0 || o.MinMargin > 1 {
+		return fmt.Errorf("minimum margin must be between 0 and 1, got %g", o.MinMargin)
+	}
+	return nil
+}
+
+// Decision is the label predicted for one row, or the unknown label with the
+// reason it was rejected.
+type Decision struct {
+	Label       string
+	Probability float64
+	Margin      float64
+	Reason      RejectReason
+}
+
+func (d Decision) Rejected() bool {
+	return d.Reason != NotRejected
+}
+
+// PredictWithRejection predicts a label per row like Predict, but gives rows
+// the model is unsure about the unknown label and a reason code.
+func (m *Model) PredictWithRejection(dataset *hogwarts.Dataset, options RejectOptions) ([]Decision, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if options.UnknownLabel == "" {
+		options.UnknownLabel = DefaultUnknownLabel
+	}
+
+	probabilities := m.PredictProba(dataset)
+	rows, _ := probabilities.Dims()
+	decisions := make([]Decision, rows)
+	for i := range decisions {
+		decisions[i] = m.decide(probabilities.RawRowView(i), options)
+	}
+	return decisions, nil
+}
+
+func (m *Model) decide(probabilities []float64, options RejectOptions) Decision {
+	best, runnerUp := -1, -1
+	for j, probability := range probabilities {
+		if math.IsNaN(probability) {
+			continue
+		}
+		if best < 0 || probability > probabilities[best] {
+			best, runnerUp = j, best
+		} else if runnerUp < 0 || probability > probabilities[runnerUp] {
+			runnerUp = j
+		}
+	}
+
+	if best < 0 || probabilities[best] <= 0 {
+		return Decision{Label: options.UnknownLabel, Reason: RejectedNoProbability}
+	}
+
+	decision := Decision{
+		Label:       m.LabelNames[best],
+		Probability: probabilities[best],
+		Margin:      probabilities[best],
+	}
+	if runnerUp >= 0 {
+		decision.Margin -= probabilities[runnerUp]
+	}
+
+	switch {
+	case decision.Probability < options.MinProbability:
+		decision.Reason = RejectedLowProbability
+	case decision.Margin < options.MinMargin:
+		decision.Reason = RejectedLowMargin
+	}
+	if decision.Rejected() {
+		decision.Label = options.UnknownLabel
+	}
+	return decision
+}
@@ -0,0 +1,158 @@
+package logisticregression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecide(t *testing.T) {
+	model := &Model{LabelNames: []string{"A", "B", "C"}}
+	nan := math.NaN()
+
+	tests := []struct {
+		name          string
+		probabilities []float64
+		options       RejectOptions
+		want          Decision
+	}{
+		{
+			name:          "no thresholds",
+			probabilities: []float64{0.25, 0.5, 0.25},
+			want:          Decision{Label: "B", Probability: 0.5, Margin: 0.25},
+		},
+		{
+			name:          "probability at the minimum",
+			probabilities: []float64{0.25, 0.5, 0.25},
+			options:       RejectOptions{MinProbability: 0.5},
+			want:          Decision{Label: "B", Probability: 0.5, Margin: 0.25},
+		},
+		{
+			name:          "probability below the minimum",
+			probabilities: []float64{0.25, 0.5, 0.25},
+			options:       RejectOptions{MinProbability: 0.625},
+			want:          Decision{Label: DefaultUnknownLabel, Probability: 0.5, Margin: 0.25, Reason: RejectedLowProbability},
+		},
+		{
+			name:          "margin at the minimum",
+			probabilities: []float64{0.25, 0.5, 0.25},
+			options:       RejectOptions{MinMargin: 0.25},
+			want:          Decision{Label: "B", Probability: 0.5, Margin: 0.25},
+		},
+		{
+			name:          "margin below the minimum",
+			probabilities: []float64{0.125, 0.5, 0.375},
+			options:       RejectOptions{MinMargin: 0.25},
+			want:          Decision{Label: DefaultUnknownLabel, Probability: 0.5, Margin: 0.125, Reason: RejectedLowMargin},
+		},
+		{
+			name:          "low probability before low margin",
+			probabilities: []float64{0.125, 0.5, 0.375},
+			options:       RejectOptions{MinProbability: 0.75, MinMargin: 0.25},
+			want:          Decision{Label: DefaultUnknownLabel, Probability: 0.5, Margin: 0.125, Reason: RejectedLowProbability},
+		},
+		{
+			name:          "tie goes to the first class",
+			probabilities: []float64{0.5, 0.5, 0},
+			want:          Decision{Label: "A", Probability: 0.5, Margin: 0},
+		},
+		{
+			name:          "tie with a margin",
+			probabilities: []float64{0.5, 0.5, 0},
+			options:       RejectOptions{MinMargin: 0.125, UnknownLabel: "?"},
+			want:          Decision{Label: "?", Probability: 0.5, Margin: 0, Reason: RejectedLowMargin},
+		},
+		{
+			name:          "missing probabilities are skipped",
+			probabilities: []float64{nan, 0.75, 0.25},
+			want:          Decision{Label: "B", Probability: 0.75, Margin: 0.5},
+		},
+		{
+			name:          "no probability",
+			probabilities: []float64{nan, nan, nan},
+			want:          Decision{Label: DefaultUnknownLabel, Reason: RejectedNoProbability},
+		},
+		{
+			name:          "zero probabilities",
+			probabilities: []float64{0, 0, 0},
+			options:       RejectOptions{UnknownLabel: "?"},
+			want:          Decision{Label: "?", Reason: RejectedNoProbability},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := test.options
+			if options.UnknownLabel == "" {
+				options.UnknownLabel = DefaultUnknownLabel
+			}
+			got := model.decide(test.probabilities, options)
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// The margin of a single class is its probability, as if the runner-up had
+// none.
+func TestDecideSingleClass(t *testing.T) {
+	model := &Model{LabelNames: []string{"A"}}
+	options := RejectOptions{MinMargin: 0.5, UnknownLabel: DefaultUnknownLabel}
+
+	if got := model.decide([]float64{1}, options); got != (Decision{Label: "A", Probability: 1, Margin: 1}) {
+		t.Errorf("certain class: got %+v", got)
+	}
+	want := Decision{Label: DefaultUnknownLabel, Probability: 0.25, Margin: 0.25, Reason: RejectedLowMargin}
+	if got := model.decide([]float64{0.25}, options); got != want {
+		t.Errorf("unsure class: got %+v, want %+v", got, want)
+	}
+}
+
+// Rejected rows carry the unknown label and a reason, and accepted rows a
+// house and no reason, which is what logregpredict counts.
+func TestPredictWithRejectionLabelsAndReasons(t *testing.T) {
+	dataset := loadTrainingDataset(t)
+	model, err := TrainNewModelWithOptions(dataset, TrainOptions{LearningRate: 0.1, Iterations: 100})
+	if err != nil {
+		t.Fatalf("training: %v", err)
+	}
+
+	options := RejectOptions{MinProbability: 0.75, MinMargin: 0.65, UnknownLabel: "Muggle"}
+	decisions, err := model.PredictWithRejection(dataset, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	predictions := model.Predict(dataset)
+
+	rejected, accepted := map[RejectReason]int{}, 0
+	for i, decision := range decisions {
+		if decision.Rejected() {
+			rejected[decision.Reason]++
+			if decision.Label != "Muggle" {
+				t.Errorf("row %d rejected for %q with label %q", i, decision.Reason, decision.Label)
+			}
+			continue
+		}
+		if decision.Label != predictions[i] {
+			t.Errorf("row %d accepted as %q, Predict gives %q", i, decision.Label, predictions[i])
+		}
+		accepted++
+		if decision.Probability < options.MinProbability || decision.Margin < options.MinMargin {
+			t.Errorf("row %d accepted with probability %g and margin %g", i, decision.Probability, decision.Margin)
+		}
+	}
+	for _, reason := range []RejectReason{RejectedLowProbability, RejectedLowMargin} {
+		if rejected[reason] == 0 {
+			t.Errorf("expected rows rejected for %q", reason)
+		}
+	}
+	if accepted == 0 {
+		t.Error("expected some rows to be accepted")
+	}
+
+	for _, options := range []RejectOptions{{MinProbability: 1.5}, {MinMargin: -0.1}} {
+		if _, err := model.PredictWithRejection(dataset, options); err == nil {
+			t.Errorf("%+v: expected an error", options)
+		}
+	}
+}
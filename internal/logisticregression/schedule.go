@@ -0,0 +1,183 @@
+package logisticregression
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+type ScheduleKind int
+
+const (
+	ConstantSchedule ScheduleKind = iota
+	// StepDecay multiplies the rate by DecayRate every StepSize epochs.
+	StepDecay
+	// ExponentialDecay multiplies the rate by DecayRate every epoch.
+	ExponentialDecay
+	// InverseTimeDecay divides the rate by 1 + DecayRate * epoch.
+	InverseTimeDecay
+	// CosineAnnealing lowers the rate towards MinLearningRate along half a
+	// cosine over the remaining epochs.
+	CosineAnnealing
+)
+
+func ParseScheduleKind(name string) (ScheduleKind, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "constant":
+		return ConstantSchedule, nil
+	case "step":
+		return StepDecay, nil
+	case "exponential":
+		return ExponentialDecay, nil
+	case "inverse-time":
+		return InverseTimeDecay, nil
+	case "cosine":
+		return CosineAnnealing, nil
+	}
+	return ConstantSchedule, fmt.Errorf("unknown learning rate schedule %q", name)
+}
+
+func (k ScheduleKind) String() string {
+	switch k {
+	case ConstantSchedule:
+		return "constant"
+	case StepDecay:
+		return "step"
+	case ExponentialDecay:
+		return "exponential"
+	case InverseTimeDecay:
+		return "inverse-time"
+	case CosineAnnealing:
+		return "cosine"
+	}
+	return fmt.Sprintf("ScheduleKind(%d)", int(k))
+}
+
+func (k ScheduleKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *ScheduleKind) UnmarshalText(text []byte) error {
+	kind, err := ParseScheduleKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+// Schedule sets the learning rate of every epoch from the base rate. A linear
+// warm-up from zero over WarmupEpochs can precede any kind; the decay then
+// starts counting after it. Only the fields used by Kind are kept, and zero is
+// taken literally; DefaultSchedule gives the usual values.
+type Schedule struct {
+	Kind            ScheduleKind `json:"kind"`
+	DecayRate       float64      `json:"decay_rate,omitempty"`
+	StepSize        int          `json:"step_size,omitempty"`
+	MinLearningRate float64      `json:"min_learning_rate,omitempty"`
+	WarmupEpochs    int          `json:"warmup_epochs,omitempty"`
+}
+
+// DefaultSchedule returns the usual parameters of kind, without warm-up.
+func DefaultSchedule(kind ScheduleKind) Schedule {
+	schedule := Schedule{Kind: kind}
+	switch kind {
+	case StepDecay:
+		schedule.DecayRate = 0.5
+		schedule.StepSize = 100
+	case ExponentialDecay:
+		schedule.DecayRate = 0.99
+	case InverseTimeDecay:
+		schedule.DecayRate = 0.01
+	}
+	return schedule
+}
+
+func (s Schedule) normalized() Schedule {
+	normalized := Schedule{Kind: s.Kind, WarmupEpochs: s.WarmupEpochs}
+	switch s.Kind {
+	case StepDecay:
+		normalized.DecayRate = s.DecayRate
+		normalized.StepSize = s.StepSize
+	case ExponentialDecay, InverseTimeDecay:
+		normalized.DecayRate = s.DecayRate
+	case CosineAnnealing:
+		normalized.MinLearningRate = s.MinLearningRate
+	}
+	return normalized
+}
+
+// MarshalJSON writes every parameter used by Kind, zero or not, like
+// OptimizerConfig.MarshalJSON.
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	s = s.normalized()
+	record := struct {
+		Kind            ScheduleKind `json:"kind"`
+		DecayRate       *float64     `json:"decay_rate,omitempty"`
+		StepSize        *int         `json:"step_size,omitempty"`
+		MinLearningRate *float64     `json:"min_learning_rate,omitempty"`
+		WarmupEpochs    int          `json:"warmup_epochs"`
+	}{Kind: s.Kind, WarmupEpochs: s.WarmupEpochs}
+	switch s.Kind {
+	case StepDecay:
+		record.DecayRate = &s.DecayRate
+		record.StepSize = &s.StepSize
+	case ExponentialDecay, InverseTimeDecay:
+		record.DecayRate = &s.DecayRate
+	case CosineAnnealing:
+		record.MinLearningRate = &s.MinLearningRate
+	}
+	return json.Marshal(record)
+}
+
+func (s Schedule) validate() error {
+	if s.Kind < ConstantSchedule || s.Kind > CosineAnnealing {
+		return fmt.Errorf("unknown learning rate schedule %v", s.Kind)
+	}
+	if s.DecayRate < 0 {
+		return errors.New("decay rate must not be negative")
+	}
+	if (s.Kind == StepDecay || s.Kind == ExponentialDecay) && (s.DecayRate <= 0 || s.DecayRate > 1) {
+		return fmt.Errorf("decay rate of the %v schedule must be in (0, 1]", s.Kind)
+	}
+	if s.Kind == StepDecay && s.StepSize <= 0 {
+		return errors.New("step size must be positive")
+	}
+	if s.MinLearningRate < 0 {
+		return errors.New("minimum learning rate must not be negative")
+	}
+	if s.WarmupEpochs < 0 {
+		return errors.New("warm-up epochs must not be negative")
+	}
+	return nil
+}
+
+// isConstant reports whether the schedule leaves the base rate unchanged.
+func (s Schedule) isConstant() bool {
+	return s.Kind == ConstantSchedule && s.WarmupEpochs == 0
+}
+
+// learningRate is the rate of the zero-based epoch out of epochs, for a
+// schedule that passed validate.
+func (s Schedule) learningRate(base float64, epoch int, epochs int) float64 {
+	if epoch < s.WarmupEpochs {
+		return base * float64(epoch+1) / float64(s.WarmupEpochs)
+	}
+	epoch -= s.WarmupEpochs
+	epochs -= s.WarmupEpochs
+
+	switch s.Kind {
+	case StepDecay:
+		return base * math.Pow(s.DecayRate, float64(epoch/s.StepSize))
+	case ExponentialDecay:
+		return base * math.Pow(s.DecayRate, float64(epoch))
+	case InverseTimeDecay:
+		return base / (1 + s.DecayRate*float64(epoch))
+	case CosineAnnealing:
+		progress := float64(epoch) / float64(max(epochs, 1))
+		return s.MinLearningRate + (base-s.MinLearningRate)*(1+math.Cos(math.Pi*progress))/2
+	}
+	return base
+}
@@ -0,0 +1,72 @@
+package logisticregression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScheduleLearningRate(t *testing.T) {
+	const base = 0.1
+	stepDecay := Schedule{Kind: StepDecay, DecayRate: 0.5, StepSize: 10}
+	exponential := Schedule{Kind: ExponentialDecay, DecayRate: 0.9}
+	cosine := Schedule{Kind: CosineAnnealing, MinLearningRate: 0.01}
+	warmExponential := Schedule{Kind: ExponentialDecay, DecayRate: 0.5, WarmupEpochs: 4}
+	warmCosine := Schedule{Kind: CosineAnnealing, MinLearningRate: 0.01, WarmupEpochs: 4}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		epoch    int
+		epochs   int
+		want     float64
+	}{
+		{"constant", Schedule{}, 57, 100, 0.1},
+		{"step before the first step", stepDecay, 9, 100, 0.1},
+		{"step after two steps", stepDecay, 25, 100, 0.025},
+		{"exponential", exponential, 2, 100, 0.081},
+		{"inverse-time", Schedule{Kind: InverseTimeDecay, DecayRate: 0.5}, 4, 100, 0.1 / 3},
+		{"cosine start", cosine, 0, 10, 0.1},
+		{"cosine halfway", cosine, 5, 10, 0.055},
+		{"warm-up first epoch", warmExponential, 0, 100, 0.025},
+		{"warm-up last epoch", warmExponential, 3, 100, 0.1},
+		{"decay after warm-up", warmExponential, 5, 100, 0.05},
+		{"cosine after warm-up", warmCosine, 9, 14, 0.055},
+		// Warm-up over every epoch leaves no epochs to anneal over.
+		{"cosine without epochs after warm-up", warmCosine, 4, 4, 0.1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.schedule.validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := test.schedule.learningRate(base, test.epoch, test.epochs)
+			if math.Abs(got-test.want) > 1e-12 {
+				t.Errorf("got %g, want %g", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantErr  bool
+	}{
+		{"unset is constant", Schedule{}, false},
+		{"default step", DefaultSchedule(StepDecay), false},
+		{"step without step size", Schedule{Kind: StepDecay, DecayRate: 0.5}, true},
+		{"step without decay rate", Schedule{Kind: StepDecay, StepSize: 10}, true},
+		{"exponential without decay rate", Schedule{Kind: ExponentialDecay}, true},
+		{"exponential growing", Schedule{Kind: ExponentialDecay, DecayRate: 1.5}, true},
+		{"inverse-time without decay", Schedule{Kind: InverseTimeDecay}, false},
+		{"negative warm-up", Schedule{WarmupEpochs: -1}, true},
+		{"negative minimum", Schedule{Kind: CosineAnnealing, MinLearningRate: -0.1}, true},
+	}
+	for _, test := range tests {
+		if err := test.schedule.validate(); (err != nil) != test.wantErr {
+			t.Errorf("%s: got error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package logisticregression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// softmaxObjective is the weighted cross-entropy of the softmax over all
+// classes. The weights are a classCount by size matrix stored row by row, one
+// row per class, and classes holds the index of each row's label.
+type softmaxObjective struct {
+	x             *mat.Dense
+	classes       []int
+	classCount    int
+	sampleWeights []float64
+}
+
+func newSoftmaxObjective(x *mat.Dense, labels []string, houses []string, sampleWeights []float64) *softmaxObjective {
+	classIndices := make(map[string]int, len(houses))
+	for k, house := range houses {
+		classIndices[house] = k
+	}
+	classes := make([]int, len(labels))
+	for i, label := range labels {
+		classes[i] = classIndices[label]
+	}
+
+	return &softmaxObjective{
+		x:             x,
+		classes:       classes,
+		classCount:    len(houses),
+		sampleWeights: sampleWeights,
+	}
+}
+
+func (o *softmaxObjective) size() int {
+	_, columns := o.x.Dims()
+	return o.classCount * columns
+}
+
+func (o *softmaxObjective) rowCount() int {
+	rows, _ := o.x.Dims()
+	return rows
+}
+
+// probabilities returns the class probabilities of the design rows x, one
+// row per sample.
+func (o *softmaxObjective) probabilities(x mat.Matrix, weights []float64) *mat.Dense {
+	_, columns := o.x.Dims()
+	var probabilities mat.Dense
+	probabilities.Mul(x, mat.NewDense(o.classCount, columns, weights).T())
+
+	rows, _ := probabilities.Dims()
+	for i := 0; i < rows; i++ {
+		softmaxInPlace(probabilities.RawRowView(i))
+	}
+	return &probabilities
+}
+
+func (o *softmaxObjective) gradient(weights []float64, rows []int, gradient []float64) float64 {
+	weightSum := 0.0
+	if !isFullBatch(rows, o.rowCount()) {
+		classWeights := splitWeights(weights, o.classCount)
+		classGradients := splitWeights(gradient, o.classCount)
+		for _, i := range rows {
+			row := o.x.RawRowView(i)
+			residuals := logits(row, classWeights)
+			softmaxInPlace(residuals)
+			residuals[o.classes[i]] -= 1.0
+			for k, residual := range residuals {
+				floats.AddScaled(classGradients[k], o.sampleWeights[i]*residual, row)
+			}
+			weightSum += o.sampleWeights[i]
+		}
+		return weightSum
+	}
+
+	residuals := o.probabilities(o.x, weights)
+	for i, class := range o.classes {
+		residual := residuals.RawRowView(i)
+		residual[class] -= 1.0
+		floats.Scale(o.sampleWeights[i], residual)
+		weightSum += o.sampleWeights[i]
+	}
+
+	var classGradients mat.Dense
+	classGradients.Mul(residuals.T(), o.x)
+	floats.Add(gradient, classGradients.RawMatrix().Data)
+	return weightSum
+}
+
+func (o *softmaxObjective) cost(weights []float64) float64 {
+	probabilities := o.probabilities(o.x, weights)
+
+	weightSum := 0.0
+	cost := 0.0
+	epsilon := 1e-15
+
+	for i, class := range o.classes {
+		cost -= o.sampleWeights[i] * math.Log(math.Max(epsilon, probabilities.At(i, class)))
+		weightSum += o.sampleWeights[i]
+	}
+
+	return cost / weightSum
+}
+
+func (o *softmaxObjective) accuracy(weights []float64) float64 {
+	probabilities := o.probabilities(o.x, weights)
+
+	weightSum := 0.0
+	correct := 0.0
+	for i, class := range o.classes {
+		if floats.MaxIdx(probabilities.RawRowView(i)) == class {
+			correct += o.sampleWeights[i]
+		}
+		weightSum += o.sampleWeights[i]
+	}
+	return correct / weightSum
+}
+
+// hessian assembles the blocks Xᵀ D_kl X / Σw between classes k and l, with
+// D_kl the diagonal of w·p_k·(δ_kl - p_l).
+func (o *softmaxObjective) hessian(weights []float64) *mat.SymDense {
+	rows, columns := o.x.Dims()
+	probabilities := o.probabilities(o.x, weights)
+	weightSum := floats.Sum(o.sampleWeights)
+
+	hessian := mat.NewSymDense(o.size(), nil)
+	scaled := mat.NewDense(rows, columns, nil)
+	var block mat.Dense
+	for k := 0; k < o.classCount; k++ {
+		for l := k; l < o.classCount; l++ {
+			for i := 0; i < rows; i++ {
+				curvature := -probabilities.At(i, k) * probabilities.At(i, l)
+				if k == l {
+					curvature += probabilities.At(i, k)
+				}
+				floats.ScaleTo(scaled.RawRowView(i), o.sampleWeights[i]*curvature/weightSum, o.x.RawRowView(i))
+			}
+			block.Mul(o.x.T(), scaled)
+
+			for a := 0; a < columns; a++ {
+				for b := 0; b < columns; b++ {
+					if k != l || a <= b {
+						hessian.SetSym(k*columns+a, l*columns+b, block.At(a, b))
+					}
+				}
+			}
+		}
+	}
+	return hessian
+}
+
+// splitWeights views a flat weight vector as one row per class, sharing the
+// underlying storage.
+func splitWeights(weights []float64, classCount int) [][]float64 {
+	size := len(weights) / classCount
+	rows := make([][]float64, classCount)
+	for k := range rows {
+		rows[k] = weights[k*size : (k+1)*size]
+	}
+	return rows
+}
+
+func logits(x []float64, weights [][]float64) []float64 {
+	z := make([]float64, len(weights))
+	for k := range weights {
+		z[k] = floats.Dot(x, weights[k])
+	}
+	return z
+}
+
+// softmaxInPlace shifts by the largest logit so the exponentials cannot
+// overflow.
+func softmaxInPlace(z []float64) {
+	largest := floats.Max(z)
+	sum := 0.0
+	for k, value := range z {
+		z[k] = math.Exp(value - largest)
+		sum += z[k]
+	}
+	floats.Scale(1/sum, z)
+}
@@ -0,0 +1,24 @@
+package logisticregression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSoftmaxInPlace(t *testing.T) {
+	tests := []struct {
+		name string
+		z    []float64
+		want []float64
+	}{
+		{"equal logits", []float64{2, 2, 2, 2}, []float64{0.25, 0.25, 0.25, 0.25}},
+		{"two classes", []float64{math.Log(3), 0}, []float64{0.75, 0.25}},
+		// Without the shift, exp(1000) would overflow to +Inf.
+		{"large logits", []float64{1000, 1000 + math.Log(3)}, []float64{0.25, 0.75}},
+		{"very negative logits", []float64{-1000, -2000}, []float64{1, 0}},
+	}
+	for _, test := range tests {
+		softmaxInPlace(test.z)
+		assertClose(t, test.name, test.z, test.want, 1e-12)
+	}
+}
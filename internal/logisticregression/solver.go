@@ -0,0 +1,354 @@
+package logisticregression
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+type Solver int
+
+const (
+	GradientDescent Solver = iota
+	// Newton is Newton's method, which for logistic regression is
+	// iteratively reweighted least squares.
+	Newton
+	LBFGS
+)
+
+func ParseSolver(name string) (Solver, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "gradient-descent", "gd":
+		return GradientDescent, nil
+	case "newton", "irls":
+		return Newton, nil
+	case "lbfgs", "l-bfgs":
+		return LBFGS, nil
+	}
+	return GradientDescent, fmt.Errorf("unknown solver %q", name)
+}
+
+func (s Solver) String() string {
+	switch s {
+	case GradientDescent:
+		return "gradient-descent"
+	case Newton:
+		return "newton"
+	case LBFGS:
+		return "lbfgs"
+	}
+	return fmt.Sprintf("Solver(%d)", int(s))
+}
+
+// Monitor is the validation metric watched by early stopping.
+type Monitor int
+
+const (
+	ValidationLoss Monitor = iota
+	ValidationAccuracy
+)
+
+func ParseMonitor(name string) (Monitor, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "loss":
+		return ValidationLoss, nil
+	case "accuracy":
+		return ValidationAccuracy, nil
+	}
+	return ValidationLoss, fmt.Errorf("unknown monitor %q", name)
+}
+
+func (m Monitor) String() string {
+	switch m {
+	case ValidationLoss:
+		return "loss"
+	case ValidationAccuracy:
+		return "accuracy"
+	}
+	return fmt.Sprintf("Monitor(%d)", int(m))
+}
+
+const (
+	defaultGradientTolerance = 1e-6
+	defaultPatience          = 10
+	defaultLogEvery          = 100
+	defaultSMOTENeighbours   = 5
+	// gradientCheckEvery is how often mini-batch gradient descent computes
+	// the full gradient for the gradient tolerance.
+	gradientCheckEvery = 10
+)
+
+const (
+	stoppedByMaxIterations     = "max-iterations"
+	stoppedByTolerance         = "tolerance"
+	stoppedByGradientTolerance = "gradient-tolerance"
+	stoppedByEarlyStopping     = "early-stopping"
+	stoppedByLineSearch        = "line-search"
+)
+
+// Diagnostics describe how training ended for one problem: one per house for
+// one-vs-rest, a single one for softmax.
+type Diagnostics struct {
+	Label      string `json:"label,omitempty"`
+	Iterations int    `json:"iterations"`
+	// BestIteration is the epoch whose weights early stopping kept.
+	BestIteration int     `json:"best_iteration,omitempty"`
+	StoppedBy     string  `json:"stopped_by"`
+	Cost          float64 `json:"cost"`
+	GradientNorm  float64 `json:"gradient_norm"`
+	Converged     bool    `json:"converged"`
+}
+
+func (d Diagnostics) String() string {
+	status := "not converged"
+	if d.Converged {
+		status = "converged"
+	}
+	iterations := fmt.Sprintf("%d iterations", d.Iterations)
+	if d.BestIteration > 0 {
+		iterations += fmt.Sprintf(" (best %d)", d.BestIteration)
+	}
+	return fmt.Sprintf("%s, stopped by %s, cost %.6f, gradient norm %.3e, %s",
+		iterations, d.StoppedBy, d.Cost, d.GradientNorm, status)
+}
+
+func solve(problem objective, validation objective, options TrainOptions, log io.Writer) ([]float64, Diagnostics) {
+	var weights []float64
+	var diagnostics Diagnostics
+	switch options.Solver {
+	case Newton:
+		weights, diagnostics = newtonMethod(problem, options, log)
+	case LBFGS:
+		weights, diagnostics = limitedMemoryBFGS(problem, options, log)
+	default:
+		weights, diagnostics = gradientDescent(problem, validation, options, log)
+	}
+
+	diagnostics.Cost = problem.cost(weights)
+	diagnostics.GradientNorm = floats.Norm(optimalityGradient(problem, weights), 2)
+	diagnostics.Converged = diagnostics.GradientNorm <= gradientTolerance(options)
+	return weights, diagnostics
+}
+
+func gradientTolerance(options TrainOptions) float64 {
+	if options.GradientTolerance == 0 {
+		return defaultGradientTolerance
+	}
+	return options.GradientTolerance
+}
+
+// optimalityGradient is the full gradient, or with an L1 penalty its
+// smallest subgradient, whose norm is zero exactly at the optimum.
+func optimalityGradient(problem objective, weights []float64) []float64 {
+	return stationarityGradient(problem, weights, fullGradient(problem, weights))
+}
+
+// stationarityGradient turns the full gradient at weights into the gradient of
+// optimalityGradient.
+func stationarityGradient(problem objective, weights []float64, gradient []float64) []float64 {
+	if regularized, ok := problem.(*regularizedObjective); ok {
+		return regularized.stationarity(weights, gradient)
+	}
+	return gradient
+}
+
+// validationScore is higher for better weights: the accuracy, or the negated
+// loss.
+func validationScore(validation objective, weights []float64, monitor Monitor) float64 {
+	if monitor == ValidationAccuracy {
+		return validation.accuracy(weights)
+	}
+	return -validation.cost(weights)
+}
+
+func relativeChange(previous float64, current float64) float64 {
+	return math.Abs(previous-current) / math.Max(math.Abs(previous), 1e-12)
+}
+
+func newtonMethod(problem objective, options TrainOptions, log io.Writer) ([]float64, Diagnostics) {
+	weights := make([]float64, problem.size())
+	cost := problem.cost(weights)
+
+	for iter := 0; iter < options.Iterations; iter++ {
+		gradient := fullGradient(problem, weights)
+		gradientNorm := floats.Norm(gradient, 2)
+		fmt.Fprintf(log, "  Iteration %d: Cost = %.6f, Gradient norm = %.3e\n", iter, cost, gradientNorm)
+		if gradientNorm <= gradientTolerance(options) {
+			return weights, Diagnostics{Iterations: iter, StoppedBy: stoppedByGradientTolerance}
+		}
+
+		direction := dampedNewtonDirection(problem.hessian(weights), gradient)
+		previousCost := cost
+		var ok bool
+		weights, cost, ok = backtrackingLineSearch(problem, weights, cost, gradient, direction)
+		if !ok {
+			return weights, Diagnostics{Iterations: iter + 1, StoppedBy: stoppedByLineSearch}
+		}
+		if options.Tolerance > 0 && relativeChange(previousCost, cost) < options.Tolerance {
+			return weights, Diagnostics{Iterations: iter + 1, StoppedBy: stoppedByTolerance}
+		}
+	}
+	return weights, Diagnostics{Iterations: options.Iterations, StoppedBy: stoppedByMaxIterations}
+}
+
+// maxNewtonDamping bounds λ in dampedNewtonDirection. A Hessian that is still
+// not positive definite there holds NaN or Inf, for example from overflowing
+// weights.
+const maxNewtonDamping = 1e10
+
+// dampedNewtonDirection solves (H + λI) d = -g, raising λ until the system is
+// positive definite. The softmax Hessian is always singular, since adding the
+// same vector to every class leaves the probabilities unchanged. Past
+// maxNewtonDamping it falls back to the steepest descent direction -g.
+func dampedNewtonDirection(hessian *mat.SymDense, gradient []float64) []float64 {
+	size := len(gradient)
+	damped := mat.NewSymDense(size, nil)
+	for damping := 1e-10; damping <= maxNewtonDamping; damping *= 10 {
+		damped.CopySym(hessian)
+		for j := 0; j < size; j++ {
+			damped.SetSym(j, j, damped.At(j, j)+damping)
+		}
+
+		var cholesky mat.Cholesky
+		if !cholesky.Factorize(damped) {
+			continue
+		}
+		var direction mat.VecDense
+		err := cholesky.SolveVecTo(&direction, mat.NewVecDense(size, gradient))
+		var condition mat.Condition
+		if err != nil && !errors.As(err, &condition) {
+			continue
+		}
+		direction.ScaleVec(-1, &direction)
+		return direction.RawVector().Data
+	}
+	return scaled(gradient, -1)
+}
+
+const lbfgsMemory = 10
+
+func limitedMemoryBFGS(problem objective, options TrainOptions, log io.Writer) ([]float64, Diagnostics) {
+	weights := make([]float64, problem.size())
+	cost := problem.cost(weights)
+	gradient := fullGradient(problem, weights)
+
+	steps := make([][]float64, 0, lbfgsMemory)
+	gradientChanges := make([][]float64, 0, lbfgsMemory)
+
+	for iter := 0; iter < options.Iterations; iter++ {
+		gradientNorm := floats.Norm(gradient, 2)
+		fmt.Fprintf(log, "  Iteration %d: Cost = %.6f, Gradient norm = %.3e\n", iter, cost, gradientNorm)
+		if gradientNorm <= gradientTolerance(options) {
+			return weights, Diagnostics{Iterations: iter, StoppedBy: stoppedByGradientTolerance}
+		}
+
+		direction := lbfgsDirection(gradient, steps, gradientChanges)
+		if floats.Dot(direction, gradient) >= 0 {
+			steps = steps[:0]
+			gradientChanges = gradientChanges[:0]
+			direction = scaled(gradient, -1)
+		}
+
+		nextWeights, nextCost, ok := backtrackingLineSearch(problem, weights, cost, gradient, direction)
+		if !ok {
+			return weights, Diagnostics{Iterations: iter + 1, StoppedBy: stoppedByLineSearch}
+		}
+		nextGradient := fullGradient(problem, nextWeights)
+
+		step := make([]float64, len(weights))
+		gradientChange := make([]float64, len(weights))
+		for j := range weights {
+			step[j] = nextWeights[j] - weights[j]
+			gradientChange[j] = nextGradient[j] - gradient[j]
+		}
+		if floats.Dot(step, gradientChange) > 1e-10 {
+			if len(steps) == lbfgsMemory {
+				steps = steps[1:]
+				gradientChanges = gradientChanges[1:]
+			}
+			steps = append(steps, step)
+			gradientChanges = append(gradientChanges, gradientChange)
+		}
+
+		previousCost := cost
+		weights, cost, gradient = nextWeights, nextCost, nextGradient
+		if options.Tolerance > 0 && relativeChange(previousCost, cost) < options.Tolerance {
+			return weights, Diagnostics{Iterations: iter + 1, StoppedBy: stoppedByTolerance}
+		}
+	}
+	return weights, Diagnostics{Iterations: options.Iterations, StoppedBy: stoppedByMaxIterations}
+}
+
+// lbfgsDirection is the two-loop recursion approximating -H⁻¹g from the most
+// recent steps and gradient changes.
+func lbfgsDirection(gradient []float64, steps [][]float64, gradientChanges [][]float64) []float64 {
+	direction := scaled(gradient, -1)
+	alphas := make([]float64, len(steps))
+	for i := len(steps) - 1; i >= 0; i-- {
+		alphas[i] = floats.Dot(steps[i], direction) / floats.Dot(gradientChanges[i], steps[i])
+		for j := range direction {
+			direction[j] -= alphas[i] * gradientChanges[i][j]
+		}
+	}
+
+	if len(steps) > 0 {
+		last := len(steps) - 1
+		gamma := floats.Dot(steps[last], gradientChanges[last]) / floats.Dot(gradientChanges[last], gradientChanges[last])
+		direction = scaled(direction, gamma)
+	}
+
+	for i := range steps {
+		beta := floats.Dot(gradientChanges[i], direction) / floats.Dot(gradientChanges[i], steps[i])
+		for j := range direction {
+			direction[j] += (alphas[i] - beta) * steps[i][j]
+		}
+	}
+	return direction
+}
+
+// backtrackingLineSearch halves the step along direction until the cost
+// decreases enough (the Armijo condition). It reports false when no step
+// improves the cost, which leaves the weights unchanged.
+func backtrackingLineSearch(problem objective, weights []float64, cost float64, gradient []float64, direction []float64) ([]float64, float64, bool) {
+	const sufficientDecrease = 1e-4
+	slope := floats.Dot(gradient, direction)
+
+	candidate := make([]float64, len(weights))
+	for step := 1.0; step > 1e-12; step /= 2 {
+		for j := range weights {
+			candidate[j] = weights[j] + step*direction[j]
+		}
+		candidateCost := problem.cost(candidate)
+		if candidateCost <= cost+sufficientDecrease*step*slope {
+			return candidate, candidateCost, true
+		}
+	}
+	return weights, cost, false
+}
+
+func fullGradient(problem objective, weights []float64) []float64 {
+	rows := make([]int, problem.rowCount())
+	for i := range rows {
+		rows[i] = i
+	}
+
+	gradient := make([]float64, len(weights))
+	weightSum := problem.gradient(weights, rows, gradient)
+	for j := range gradient {
+		gradient[j] /= weightSum
+	}
+	return gradient
+}
+
+func scaled(values []float64, factor float64) []float64 {
+	result := make([]float64, len(values))
+	for i := range values {
+		result[i] = values[i] * factor
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package logisticregression
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestDampedNewtonDirectionSolvesPositiveDefiniteSystem(t *testing.T) {
+	hessian := mat.NewSymDense(2, []float64{4, 1, 1, 3})
+	direction := dampedNewtonDirection(hessian, []float64{1, 2})
+
+	// (H + λI) d = -g with λ = 1e-10 is H d = -g to well within 1e-9.
+	want := []float64{-1.0 / 11.0, -7.0 / 11.0}
+	for i := range want {
+		if math.Abs(direction[i]-want[i]) > 1e-9 {
+			t.Errorf("direction[%d] = %g, want %g", i, direction[i], want[i])
+		}
+	}
+}
+
+func TestDampedNewtonDirectionFallsBackOnNonFiniteHessian(t *testing.T) {
+	for _, value := range []float64{math.NaN(), math.Inf(-1)} {
+		hessian := mat.NewSymDense(2, []float64{value, 0, 0, 1})
+		direction := dampedNewtonDirection(hessian, []float64{1, -2})
+
+		want := []float64{-1, 2}
+		for i := range want {
+			if direction[i] != want[i] {
+				t.Errorf("hessian with %g: direction[%d] = %g, want %g", value, i, direction[i], want[i])
+			}
+		}
+	}
+}
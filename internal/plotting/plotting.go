@@ -0,0 +1,111 @@
+package plotting
+
+import (
+	"dslx/internal/stats"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+var HouseColors = map[string]color.RGBA{
+	"Gryffindor": {R: 116, G: 0, B: 1, A: 248},
+	"Hufflepuff": {R: 97, G: 75, B: 58, A: 248},
+	"Ravenclaw":  {R: 14, G: 26, B: 64, A: 248},
+	"Slytherin":  {R: 26, G: 71, B: 42, A: 248},
+}
+
+// NewHistogram bins the values into the given edges. Histograms of the same
+// feature built from the same edges line up bar for bar.
+func NewHistogram(values []float64, edges []float64) *plotter.Histogram {
+	counts := stats.Histogram(values, edges)
+	bins := make([]plotter.HistogramBin, len(counts))
+	for i := range counts {
+		bins[i] = plotter.HistogramBin{
+			Min:    edges[i],
+			Max:    edges[i+1],
+			Weight: counts[i],
+		}
+	}
+
+	return &plotter.Histogram{
+		Bins:      bins,
+		Width:     edges[1] - edges[0],
+		FillColor: color.Gray{Y: 128},
+		LineStyle: plotter.DefaultLineStyle,
+	}
+}
+
+type DensityMode int
+
+const (
+	DensityNone DensityMode = iota
+	DensityOverlay
+	DensityReplace
+)
+
+func ParseDensityMode(name string) (DensityMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "none":
+		return DensityNone, nil
+	case "overlay":
+		return DensityOverlay, nil
+	case "replace":
+		return DensityReplace, nil
+	}
+	return 0, fmt.Errorf("unknown density mode %q", name)
+}
+
+type DensityOptions struct {
+	Mode      DensityMode
+	Kernel    stats.Kernel
+	Bandwidth stats.BandwidthMethod
+}
+
+func ParseDensityOptions(modeName string, kernelName string, bandwidthName string) (DensityOptions, error) {
+	mode, err := ParseDensityMode(modeName)
+	if err != nil {
+		return DensityOptions{}, err
+	}
+	kernel, err := stats.ParseKernel(kernelName)
+	if err != nil {
+		return DensityOptions{}, err
+	}
+	bandwidth, err := stats.ParseBandwidthMethod(bandwidthName)
+	if err != nil {
+		return DensityOptions{}, err
+	}
+
+	return DensityOptions{
+		Mode:      mode,
+		Kernel:    kernel,
+		Bandwidth: bandwidth,
+	}, nil
+}
+
+const densityGridPoints = 200
+
+// DensityLine draws the kernel density estimate of the values between min and
+// max. The density is multiplied by scale, so it can be laid over a histogram
+// of counts by passing the number of values times the bin width.
+func DensityLine(values []float64, min float64, max float64, scale float64, options DensityOptions) (*plotter.Line, error) {
+	kde, err := stats.NewKDEWithBandwidthMethod(values, options.Kernel, options.Bandwidth)
+	if err != nil {
+		return nil, err
+	}
+
+	xs, densities := kde.EvaluateGrid(min, max, densityGridPoints)
+	xys := make(plotter.XYs, len(xs))
+	for i := range xs {
+		xys[i] = plotter.XY{X: xs[i], Y: densities[i] * scale}
+	}
+
+	line, err := plotter.NewLine(xys)
+	if err != nil {
+		return nil, err
+	}
+	line.LineStyle.Width = vg.Points(1.5)
+	return line, nil
+}
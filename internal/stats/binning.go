@@ -0,0 +1,158 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxBins = 1000
+
+type BinRule int
+
+const (
+	BinSturges BinRule = iota
+	BinScott
+	BinFreedmanDiaconis
+	BinSqrt
+	BinFixedWidth
+	BinFixedCount
+)
+
+type Binning struct {
+	Rule  BinRule
+	Count int
+	Width float64
+}
+
+// ParseBinning accepts a rule name (sturges, scott, fd, sqrt), a bin count
+// such as "20" or a bin width such as "width=0.5".
+func ParseBinning(spec string) (Binning, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "sturges":
+		return Binning{Rule: BinSturges}, nil
+	case "scott":
+		return Binning{Rule: BinScott}, nil
+	case "fd", "freedman-diaconis":
+		return Binning{Rule: BinFreedmanDiaconis}, nil
+	case "sqrt":
+		return Binning{Rule: BinSqrt}, nil
+	}
+
+	if width, ok := strings.CutPrefix(spec, "width="); ok {
+		value, err := strconv.ParseFloat(width, 64)
+		if err != nil || !(value > 0) || math.IsInf(value, 0) {
+			return Binning{}, fmt.Errorf("invalid bin width %q", width)
+		}
+		return Binning{Rule: BinFixedWidth, Width: value}, nil
+	}
+
+	count, err := strconv.Atoi(spec)
+	if err != nil || count <= 0 {
+		return Binning{}, fmt.Errorf("unknown binning %q", spec)
+	}
+	return Binning{Rule: BinFixedCount, Count: count}, nil
+}
+
+// BinEdges returns ascending bin edges covering every value. Rules that yield a
+// zero width, such as Freedman-Diaconis on data with a zero interquartile
+// range, fall back to Sturges.
+func BinEdges(values []float64, binning Binning) ([]float64, error) {
+	values = RemoveMissingValues(values)
+	if len(values) == 0 {
+		return nil, ErrNoData
+	}
+	sort.Float64s(values)
+
+	min := values[0]
+	max := values[len(values)-1]
+	if min == max {
+		return []float64{min - 0.5, max + 0.5}, nil
+	}
+
+	n := float64(len(values))
+	dataRange := max - min
+
+	count := 0
+	switch binning.Rule {
+	case BinScott:
+		count = countForWidth(dataRange, math.Cbrt(24*math.Sqrt(math.Pi)/n)*Std(values))
+	case BinFreedmanDiaconis:
+		iqr := Percentile(values, 0.75) - Percentile(values, 0.25)
+		count = countForWidth(dataRange, 2*iqr*math.Cbrt(1/n))
+	case BinSqrt:
+		count = int(math.Ceil(math.Sqrt(n)))
+	case BinFixedWidth:
+		if !(binning.Width > 0) {
+			return nil, fmt.Errorf("invalid bin width %v", binning.Width)
+		}
+		return fixedWidthEdges(min, max, binning.Width), nil
+	case BinFixedCount:
+		if binning.Count <= 0 {
+			return nil, fmt.Errorf("invalid bin count %d", binning.Count)
+		}
+		count = binning.Count
+	}
+	if count <= 0 {
+		count = int(math.Ceil(math.Log2(n))) + 1
+	}
+	if count > maxBins {
+		count = maxBins
+	}
+
+	edges := make([]float64, count+1)
+	for i := range edges {
+		edges[i] = min + dataRange*float64(i)/float64(count)
+	}
+	edges[count] = max
+	return edges, nil
+}
+
+func countForWidth(dataRange float64, width float64) int {
+	if !(width > 0) {
+		return 0
+	}
+	return int(math.Ceil(dataRange / width))
+}
+
+// Fixed-width edges are aligned to multiples of the width, so bins line up
+// across plots of the same feature.
+func fixedWidthEdges(min float64, max float64, width float64) []float64 {
+	start := math.Floor(min/width) * width
+	count := int(math.Floor((max-start)/width)) + 1
+	if count > maxBins {
+		count = maxBins
+		width = (max - start) / float64(count)
+	}
+
+	edges := make([]float64, count+1)
+	for i := range edges {
+		edges[i] = start + width*float64(i)
+	}
+	return edges
+}
+
+// Histogram counts the values in each bin. Bins include their lower edge, and
+// the last bin also includes its upper edge.
+func Histogram(values []float64, edges []float64) []float64 {
+	counts := make([]float64, len(edges)-1)
+	for _, value := range values {
+		if math.IsNaN(value) || value < edges[0] || value > edges[len(edges)-1] {
+			continue
+		}
+
+		bin := sort.SearchFloat64s(edges, value)
+		if bin < len(edges) && edges[bin] == value {
+			bin++
+		}
+		bin--
+		if bin >= len(counts) {
+			bin = len(counts) - 1
+		}
+		counts[bin]++
+	}
+	return counts
+}
@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var binningSample = []float64{2.1, 3.4, 1.9, 5.6, 4.4, 3.3, 2.8, 7.9, 3.0, 4.1, 2.5, 3.9, 6.2, 3.6, 2.2, 4.8, 3.1, 9.5, 2.9, 3.8}
+
+// The expected edges are numpy.histogram_bin_edges(values, bins=rule), from a
+// Python transcription of numpy's _hist_bin_* width rules.
+func TestBinEdgesMatchesNumPy(t *testing.T) {
+	tests := []struct {
+		binning Binning
+		want    []float64
+	}{
+		{Binning{Rule: BinSturges}, []float64{1.9, 3.1666666666666665, 4.433333333333334, 5.699999999999999, 6.966666666666667, 8.233333333333333, 9.5}},
+		{Binning{Rule: BinScott}, []float64{1.9, 3.8, 5.699999999999999, 7.6, 9.5}},
+		{Binning{Rule: BinFreedmanDiaconis}, []float64{1.9, 2.9857142857142858, 4.071428571428571, 5.157142857142857, 6.242857142857142, 7.328571428571427, 8.414285714285715, 9.5}},
+		{Binning{Rule: BinSqrt}, []float64{1.9, 3.42, 4.9399999999999995, 6.460000000000001, 7.98, 9.5}},
+		{Binning{Rule: BinFixedCount, Count: 2}, []float64{1.9, 5.7, 9.5}},
+	}
+	for _, test := range tests {
+		got, err := BinEdges(append([]float64{math.NaN()}, binningSample...), test.binning)
+		if err != nil {
+			t.Errorf("%+v: unexpected error: %v", test.binning, err)
+			continue
+		}
+		assertEdges(t, test.binning, got, test.want)
+	}
+}
+
+func TestBinEdgesSpecialCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []float64
+		binning Binning
+		want    []float64
+	}{
+		{"fixed width aligned to multiples", binningSample, Binning{Rule: BinFixedWidth, Width: 2}, []float64{0, 2, 4, 6, 8, 10}},
+		{"constant values", []float64{3, 3, 3}, Binning{Rule: BinFreedmanDiaconis}, []float64{2.5, 3.5}},
+		// A zero interquartile range falls back to Sturges, ceil(log2(6)) + 1 bins.
+		{"zero interquartile range", []float64{1, 1, 1, 1, 1, 7}, Binning{Rule: BinFreedmanDiaconis}, []float64{1, 2.5, 4, 5.5, 7}},
+	}
+	for _, test := range tests {
+		got, err := BinEdges(test.values, test.binning)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		assertEdges(t, test.name, got, test.want)
+	}
+
+	edges, err := BinEdges(binningSample, Binning{Rule: BinFixedCount, Count: 5000})
+	if err != nil || len(edges) != maxBins+1 {
+		t.Errorf("too many bins: got %d edges, %v, want %d", len(edges), err, maxBins+1)
+	}
+}
+
+func TestBinEdgesErrors(t *testing.T) {
+	if _, err := BinEdges([]float64{math.NaN()}, Binning{}); !errors.Is(err, ErrNoData) {
+		t.Errorf("no values: got %v, want %v", err, ErrNoData)
+	}
+	for _, binning := range []Binning{{Rule: BinFixedWidth}, {Rule: BinFixedCount}} {
+		if _, err := BinEdges(binningSample, binning); err == nil {
+			t.Errorf("%+v: expected an error", binning)
+		}
+	}
+}
+
+func assertEdges(t *testing.T, name any, got []float64, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%v: got %v, want %v", name, got, want)
+		return
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Errorf("%v: got %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+// The expected counts are numpy.histogram(values, bins=edges)[0], which puts
+// the maximum in the last bin.
+func TestHistogramMatchesNumPy(t *testing.T) {
+	values := append([]float64{math.NaN(), 0, 12}, binningSample...)
+	got := Histogram(values, []float64{1.9, 3.8, 5.7, 7.6, 9.5})
+	if want := []float64{11, 6, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseBinning(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Binning
+	}{
+		{"sturges", Binning{Rule: BinSturges}},
+		{" Scott ", Binning{Rule: BinScott}},
+		{"fd", Binning{Rule: BinFreedmanDiaconis}},
+		{"freedman-diaconis", Binning{Rule: BinFreedmanDiaconis}},
+		{"sqrt", Binning{Rule: BinSqrt}},
+		{"20", Binning{Rule: BinFixedCount, Count: 20}},
+		{"width=0.5", Binning{Rule: BinFixedWidth, Width: 0.5}},
+	}
+	for _, test := range tests {
+		got, err := ParseBinning(test.spec)
+		if err != nil || got != test.want {
+			t.Errorf("%q: got %+v, %v, want %+v", test.spec, got, err, test.want)
+		}
+	}
+
+	for _, spec := range []string{"auto", "0", "-3", "width=0", "width=-1", "width=inf", "width=NaN", "width="} {
+		if got, err := ParseBinning(spec); err == nil {
+			t.Errorf("%q: got %+v, want an error", spec, got)
+		}
+	}
+}
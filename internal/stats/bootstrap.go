@@ -0,0 +1,255 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+const (
+	defaultBootstrapResamples = 2000
+	// maxJackknifeGroups bounds the evaluations of the statistic spent on the
+	// BCa acceleration.
+	maxJackknifeGroups = 200
+)
+
+var ErrInvalidConfidenceLevel = errors.New("confidence level must be between 0 and 1")
+
+// IndexStatistic computes a statistic from the observations at the given
+// indices. Bootstrap resamples pass repeated indices.
+type IndexStatistic func(indices []int) float64
+
+type BootstrapOptions struct {
+	Resamples int
+	Seed      int64
+	// Strata optionally assigns every observation to a group. Resamples then
+	// draw from each group separately and keep the group sizes.
+	Strata []string
+}
+
+type IntervalMethod int
+
+const (
+	IntervalPercentile IntervalMethod = iota
+	IntervalBCa
+)
+
+func ParseIntervalMethod(name string) (IntervalMethod, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "percentile":
+		return IntervalPercentile, nil
+	case "bca":
+		return IntervalBCa, nil
+	}
+	return 0, fmt.Errorf("unknown confidence interval method %q", name)
+}
+
+func (m IntervalMethod) String() string {
+	switch m {
+	case IntervalPercentile:
+		return "percentile"
+	case IntervalBCa:
+		return "bca"
+	}
+	return fmt.Sprintf("IntervalMethod(%d)", int(m))
+}
+
+type ConfidenceInterval struct {
+	Estimate float64
+	Lower    float64
+	Upper    float64
+	Level    float64
+}
+
+func ValuesStatistic(values []float64, statistic func([]float64) float64) IndexStatistic {
+	return func(indices []int) float64 {
+		sample := make([]float64, len(indices))
+		for i, index := range indices {
+			sample[i] = values[index]
+		}
+		return statistic(sample)
+	}
+}
+
+func PairedStatistic(xValues []float64, yValues []float64, statistic func([]float64, []float64) float64) IndexStatistic {
+	return func(indices []int) float64 {
+		xSample := make([]float64, len(indices))
+		ySample := make([]float64, len(indices))
+		for i, index := range indices {
+			xSample[i] = xValues[index]
+			ySample[i] = yValues[index]
+		}
+		return statistic(xSample, ySample)
+	}
+}
+
+// Bootstrap returns the statistic evaluated on resamples of n observations.
+// Resamples where the statistic is NaN are dropped.
+func Bootstrap(n int, statistic IndexStatistic, options BootstrapOptions) ([]float64, error) {
+	if n == 0 {
+		return nil, ErrNoData
+	}
+	if options.Strata != nil && len(options.Strata) != n {
+		return nil, ErrLengthMismatch
+	}
+
+	resamples := options.Resamples
+	if resamples <= 0 {
+		resamples = defaultBootstrapResamples
+	}
+
+	groups := bootstrapGroups(n, options.Strata)
+	random := rand.New(rand.NewSource(options.Seed))
+
+	replicates := make([]float64, 0, resamples)
+	indices := make([]int, n)
+	for range resamples {
+		position := 0
+		for _, group := range groups {
+			for range group {
+				indices[position] = group[random.Intn(len(group))]
+				position++
+			}
+		}
+
+		replicate := statistic(indices)
+		if !math.IsNaN(replicate) {
+			replicates = append(replicates, replicate)
+		}
+	}
+	if len(replicates) == 0 {
+		return nil, ErrNoData
+	}
+
+	return replicates, nil
+}
+
+// Groups are kept in order of first appearance so a seed always draws the same
+// resamples.
+func bootstrapGroups(n int, strata []string) [][]int {
+	if strata == nil {
+		group := make([]int, n)
+		for i := range group {
+			group[i] = i
+		}
+		return [][]int{group}
+	}
+
+	groupIndices := make(map[string]int)
+	groups := make([][]int, 0)
+	for i, stratum := range strata {
+		groupIndex, ok := groupIndices[stratum]
+		if !ok {
+			groupIndex = len(groups)
+			groupIndices[stratum] = groupIndex
+			groups = append(groups, nil)
+		}
+		groups[groupIndex] = append(groups[groupIndex], i)
+	}
+	return groups
+}
+
+func BootstrapConfidenceInterval(n int, statistic IndexStatistic, level float64, method IntervalMethod, options BootstrapOptions) (ConfidenceInterval, error) {
+	if level <= 0.0 || level >= 1.0 {
+		return ConfidenceInterval{}, ErrInvalidConfidenceLevel
+	}
+
+	allIndices := make([]int, n)
+	for i := range allIndices {
+		allIndices[i] = i
+	}
+	estimate := statistic(allIndices)
+	if math.IsNaN(estimate) {
+		return ConfidenceInterval{}, ErrNoData
+	}
+
+	replicates, err := Bootstrap(n, statistic, options)
+	if err != nil {
+		return ConfidenceInterval{}, err
+	}
+
+	alpha := (1.0 - level) / 2
+	lowerP, upperP := alpha, 1.0-alpha
+	if method == IntervalBCa {
+		lowerP, upperP = bcaPercentiles(statistic, n, estimate, replicates, alpha)
+	}
+
+	return ConfidenceInterval{
+		Estimate: estimate,
+		Lower:    PercentileWithMethod(replicates, lowerP, PercentileLinear),
+		Upper:    PercentileWithMethod(replicates, upperP, PercentileLinear),
+		Level:    level,
+	}, nil
+}
+
+// bcaPercentiles shifts the percentile interval by the bias of the bootstrap
+// distribution and corrects it for skewness with a jackknife estimate of the
+// acceleration (Efron, 1987).
+func bcaPercentiles(statistic IndexStatistic, n int, estimate float64, replicates []float64, alpha float64) (float64, float64) {
+	below := 0.0
+	for _, replicate := range replicates {
+		if replicate < estimate {
+			below++
+		} else if replicate == estimate {
+			below += 0.5
+		}
+	}
+	bias := NormalQuantile(below / float64(len(replicates)))
+	if math.IsInf(bias, 0) {
+		return alpha, 1.0 - alpha
+	}
+
+	acceleration := jackknifeAcceleration(statistic, n)
+
+	adjust := func(p float64) float64 {
+		z := NormalQuantile(p)
+		return NormalCDF(bias + (bias+z)/(1-acceleration*(bias+z)))
+	}
+
+	lower, upper := adjust(alpha), adjust(1.0-alpha)
+	if lower > upper {
+		lower, upper = upper, lower
+	}
+	return lower, upper
+}
+
+// jackknifeAcceleration estimates the BCa acceleration from the statistic
+// with each observation left out in turn. Larger samples leave out one of
+// maxJackknifeGroups interleaved groups instead, a grouped jackknife that
+// costs a fixed number of evaluations rather than n.
+func jackknifeAcceleration(statistic IndexStatistic, n int) float64 {
+	groups := min(n, maxJackknifeGroups)
+	jackknife := make([]float64, 0, groups)
+	indices := make([]int, 0, n)
+	for group := range groups {
+		indices = indices[:0]
+		for i := range n {
+			if i%groups != group {
+				indices = append(indices, i)
+			}
+		}
+
+		value := statistic(indices)
+		if !math.IsNaN(value) {
+			jackknife = append(jackknife, value)
+		}
+	}
+	if len(jackknife) == 0 {
+		return 0.0
+	}
+
+	jackknifeMean := Mean(jackknife)
+	numerator := 0.0
+	denominator := 0.0
+	for _, value := range jackknife {
+		deviation := jackknifeMean - value
+		numerator += math.Pow(deviation, 3)
+		denominator += math.Pow(deviation, 2)
+	}
+	if denominator == 0 {
+		return 0.0
+	}
+	return numerator / (6 * math.Pow(denominator, 1.5))
+}
@@ -0,0 +1,181 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestBootstrapIsDeterministic(t *testing.T) {
+	values := []float64{3, 1, 4, 1, 5, 9, 2, 6}
+	statistic := ValuesStatistic(values, Mean)
+
+	first, err := Bootstrap(len(values), statistic, BootstrapOptions{Resamples: 50, Seed: 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := Bootstrap(len(values), statistic, BootstrapOptions{Resamples: 50, Seed: 7})
+	if !reflect.DeepEqual(first, second) {
+		t.Error("seed 7 gave two different sets of replicates")
+	}
+	if len(first) != 50 {
+		t.Errorf("got %d replicates, want 50", len(first))
+	}
+
+	defaults, _ := Bootstrap(len(values), statistic, BootstrapOptions{})
+	if len(defaults) != defaultBootstrapResamples {
+		t.Errorf("got %d replicates by default, want %d", len(defaults), defaultBootstrapResamples)
+	}
+}
+
+func TestBootstrapDropsMissingReplicates(t *testing.T) {
+	// The statistic is missing whenever the first draw is observation 0.
+	statistic := func(indices []int) float64 {
+		if indices[0] == 0 {
+			return math.NaN()
+		}
+		return 1
+	}
+	replicates, err := Bootstrap(4, statistic, BootstrapOptions{Resamples: 200, Seed: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(replicates) == 200 || len(replicates) < 100 {
+		t.Errorf("kept %d of 200 replicates, want about 150", len(replicates))
+	}
+
+	missing := func(indices []int) float64 { return math.NaN() }
+	if _, err := Bootstrap(4, missing, BootstrapOptions{Resamples: 10}); !errors.Is(err, ErrNoData) {
+		t.Errorf("always missing: got error %v, want %v", err, ErrNoData)
+	}
+}
+
+func TestBootstrapStrataKeepGroupSizes(t *testing.T) {
+	strata := []string{"a", "b", "a", "b", "b"}
+	statistic := func(indices []int) float64 {
+		count := 0
+		for _, index := range indices {
+			if strata[index] == "a" {
+				count++
+			}
+		}
+		return float64(count)
+	}
+
+	replicates, err := Bootstrap(len(strata), statistic, BootstrapOptions{Resamples: 100, Seed: 3, Strata: strata})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, replicate := range replicates {
+		if replicate != 2 {
+			t.Fatalf("a resample drew %g rows of stratum a, want 2", replicate)
+		}
+	}
+
+	if _, err := Bootstrap(4, statistic, BootstrapOptions{Strata: strata}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("short strata: got error %v, want %v", err, ErrLengthMismatch)
+	}
+}
+
+func TestBootstrapConfidenceInterval(t *testing.T) {
+	values := []float64{2.1, 3.4, 1.9, 5.6, 4.4, 3.3, 2.8, 4.9, 3.7, 4.1, 2.5, 3.9, 6.2, 3.0, 4.6}
+	statistic := ValuesStatistic(values, Mean)
+	options := BootstrapOptions{Resamples: 1000, Seed: 11}
+
+	for _, method := range []IntervalMethod{IntervalPercentile, IntervalBCa} {
+		interval, err := BootstrapConfidenceInterval(len(values), statistic, 0.9, method, options)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", method, err)
+		}
+		if interval.Estimate != Mean(values) || interval.Level != 0.9 {
+			t.Errorf("%s: got estimate %g at level %g", method, interval.Estimate, interval.Level)
+		}
+		if !(interval.Lower < interval.Estimate && interval.Estimate < interval.Upper) {
+			t.Errorf("%s: interval [%g, %g] misses the estimate %g", method, interval.Lower, interval.Upper, interval.Estimate)
+		}
+	}
+
+	constant := ValuesStatistic([]float64{5, 5, 5, 5}, Mean)
+	interval, err := BootstrapConfidenceInterval(4, constant, 0.95, IntervalBCa, options)
+	if err != nil {
+		t.Fatalf("constant: unexpected error: %v", err)
+	}
+	if interval.Lower != 5 || interval.Upper != 5 {
+		t.Errorf("constant: got [%g, %g], want [5, 5]", interval.Lower, interval.Upper)
+	}
+
+	for _, level := range []float64{0, 1, 95} {
+		if _, err := BootstrapConfidenceInterval(4, constant, level, IntervalPercentile, options); !errors.Is(err, ErrInvalidConfidenceLevel) {
+			t.Errorf("level %g: got error %v, want %v", level, err, ErrInvalidConfidenceLevel)
+		}
+	}
+}
+
+// The expected percentiles follow the BCa interval of scipy.stats.bootstrap:
+// 3 of the 10 replicates lie below the estimate, and the jackknife of the mean
+// of [1, 2, 3, 4, 10] gives the acceleration.
+func TestBCaPercentilesMatchSciPy(t *testing.T) {
+	statistic := ValuesStatistic([]float64{1, 2, 3, 4, 10}, Mean)
+	replicates := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	if got := jackknifeAcceleration(statistic, 5); math.Abs(got-0.08485281374238571) > 1e-15 {
+		t.Errorf("acceleration: got %.17g, want 0.08485281374238571", got)
+	}
+	lower, upper := bcaPercentiles(statistic, 5, 3.5, replicates, 0.05)
+	if math.Abs(lower-0.009225606738517467) > 1e-12 || math.Abs(upper-0.7623152690723474) > 1e-12 {
+		t.Errorf("got [%.17g, %.17g], want [0.009225606738517467, 0.7623152690723474]", lower, upper)
+	}
+}
+
+// For the mean, leaving out single observations gives an acceleration of
+// Σd³ / (6 (Σd²)^1.5) over the deviations d from the mean.
+func TestJackknifeAccelerationOfMean(t *testing.T) {
+	values := make([]float64, maxJackknifeGroups)
+	for i := range values {
+		values[i] = math.Exp(float64(i%17) / 4)
+	}
+	mean := Mean(values)
+	cubes, squares := 0.0, 0.0
+	for _, value := range values {
+		cubes += math.Pow(value-mean, 3)
+		squares += math.Pow(value-mean, 2)
+	}
+
+	got := jackknifeAcceleration(ValuesStatistic(values, Mean), len(values))
+	if want := cubes / (6 * math.Pow(squares, 1.5)); math.Abs(got-want) > 1e-12 {
+		t.Errorf("got %.15g, want %.15g", got, want)
+	}
+}
+
+// Above maxJackknifeGroups observations, every evaluation leaves out one of
+// the interleaved groups.
+func TestJackknifeAccelerationGroupsLargeSamples(t *testing.T) {
+	const n = 5 * maxJackknifeGroups
+	left := make(map[int]int)
+	statistic := func(indices []int) float64 {
+		if len(indices) != n-5 {
+			t.Fatalf("evaluated on %d observations, want %d", len(indices), n-5)
+		}
+		present := make(map[int]bool, len(indices))
+		for _, index := range indices {
+			present[index] = true
+		}
+		for i := range n {
+			if !present[i] {
+				left[i]++
+			}
+		}
+		return 0
+	}
+
+	jackknifeAcceleration(statistic, n)
+	if len(left) != n {
+		t.Errorf("left out %d observations, want all %d", len(left), n)
+	}
+	for i, count := range left {
+		if count != 1 {
+			t.Errorf("observation %d left out %d times", i, count)
+		}
+	}
+}
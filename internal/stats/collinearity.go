@@ -0,0 +1,168 @@
+package stats
+
+import "math"
+
+// CovarianceMatrix of the columns of rows. Like pandas, every pair of columns
+// uses the rows where both values are present.
+func CovarianceMatrix(rows [][]float64, ddof int) ([][]float64, error) {
+	return pairwiseMatrix(rows, func(x []float64, y []float64) (float64, error) {
+		return CovarianceE(x, y, ddof)
+	})
+}
+
+func CorrelationMatrix(rows [][]float64) ([][]float64, error) {
+	return pairwiseMatrix(rows, CalculateCorrelationE)
+}
+
+func pairwiseMatrix(rows [][]float64, pairStatistic func([]float64, []float64) (float64, error)) ([][]float64, error) {
+	columns := transpose(rows)
+
+	matrix := make([][]float64, len(columns))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(columns))
+	}
+	for i := range columns {
+		for j := i; j < len(columns); j++ {
+			value, err := pairStatistic(columns[i], columns[j])
+			if err != nil {
+				return nil, err
+			}
+			matrix[i][j] = value
+			matrix[j][i] = value
+		}
+	}
+	return matrix, nil
+}
+
+// VarianceInflationFactors returns 1 / (1 - R²) for every column, where R² is
+// the fit of the column on all the others over the complete rows. The fit uses
+// a pseudo-inverse, so perfectly collinear columns get an infinite factor
+// without hiding the factors of the rest.
+func VarianceInflationFactors(rows [][]float64) ([]float64, error) {
+	complete := completeRows(rows)
+	if len(complete) < 2 {
+		return nil, ErrTooFewValues
+	}
+
+	correlations, err := CorrelationMatrix(complete)
+	if err != nil {
+		return nil, err
+	}
+
+	factors := make([]float64, len(correlations))
+	for target := range correlations {
+		others := make([]int, 0, len(correlations)-1)
+		for i := range correlations {
+			if i != target {
+				others = append(others, i)
+			}
+		}
+
+		otherCorrelations := make([][]float64, len(others))
+		targetCorrelations := make([]float64, len(others))
+		for i, row := range others {
+			otherCorrelations[i] = make([]float64, len(others))
+			for j, column := range others {
+				otherCorrelations[i][j] = correlations[row][column]
+			}
+			targetCorrelations[i] = correlations[row][target]
+		}
+
+		values, vectors, err := SymmetricEigen(otherCorrelations)
+		if err != nil {
+			return nil, err
+		}
+
+		rSquared := 0.0
+		for k := range values {
+			if values[k] <= values[0]*1e-12 {
+				continue
+			}
+			projection := 0.0
+			for i := range vectors[k] {
+				projection += vectors[k][i] * targetCorrelations[i]
+			}
+			rSquared += projection * projection / values[k]
+		}
+
+		if rSquared >= 1-1e-12 {
+			factors[target] = math.Inf(1)
+		} else {
+			factors[target] = 1 / (1 - rSquared)
+		}
+	}
+	return factors, nil
+}
+
+// ConditionNumber of a design matrix is the ratio of its largest to its
+// smallest singular value, computed from the eigenvalues of XᵀX over the
+// complete rows. The matrix is singular when the smallest is zero, and the
+// condition number is then infinite.
+func ConditionNumber(rows [][]float64) (float64, error) {
+	complete := completeRows(rows)
+	if len(complete) == 0 {
+		return 0.0, ErrNoData
+	}
+
+	numColumns := len(complete[0])
+	gram := make([][]float64, numColumns)
+	for i := range gram {
+		gram[i] = make([]float64, numColumns)
+	}
+	for _, row := range complete {
+		for i := range numColumns {
+			for j := i; j < numColumns; j++ {
+				gram[i][j] += row[i] * row[j]
+			}
+		}
+	}
+	for i := range numColumns {
+		for j := 0; j < i; j++ {
+			gram[i][j] = gram[j][i]
+		}
+	}
+
+	values, _, err := SymmetricEigen(gram)
+	if err != nil {
+		return 0.0, err
+	}
+
+	largest := values[0]
+	smallest := values[len(values)-1]
+	if smallest <= largest*1e-15 {
+		return math.Inf(1), nil
+	}
+	return math.Sqrt(largest / smallest), nil
+}
+
+func completeRows(rows [][]float64) [][]float64 {
+	complete := make([][]float64, 0, len(rows))
+	for _, row := range rows {
+		hasMissing := false
+		for _, value := range row {
+			if math.IsNaN(value) {
+				hasMissing = true
+				break
+			}
+		}
+		if !hasMissing {
+			complete = append(complete, row)
+		}
+	}
+	return complete
+}
+
+func transpose(rows [][]float64) [][]float64 {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	columns := make([][]float64, len(rows[0]))
+	for j := range columns {
+		columns[j] = make([]float64, len(rows))
+		for i := range rows {
+			columns[j][i] = rows[i][j]
+		}
+	}
+	return columns
+}
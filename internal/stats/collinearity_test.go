@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestVarianceInflationFactors(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name string
+		rows [][]float64
+		want []float64
+	}{
+		{
+			name: "uncorrelated",
+			rows: [][]float64{{1, 1}, {-1, 1}, {1, -1}, {-1, -1}},
+			want: []float64{1, 1},
+		},
+		{
+			// The columns correlate at 0.8, so each explains R² = 0.64 of
+			// the other.
+			name: "correlated",
+			rows: [][]float64{{1, 2}, {2, 1}, {3, 4}, {4, 3}, {5, 5}},
+			want: []float64{1 / (1 - 0.64), 1 / (1 - 0.64)},
+		},
+		{
+			name: "rows with missing values are skipped",
+			rows: [][]float64{{1, 2}, {2, 1}, {nan, 9}, {3, 4}, {4, 3}, {5, 5}, {7, nan}},
+			want: []float64{1 / (1 - 0.64), 1 / (1 - 0.64)},
+		},
+		{
+			// The second column is twice the first; the third stays
+			// independent of both.
+			name: "perfectly collinear",
+			rows: [][]float64{{1, 2, 1}, {-1, -2, 1}, {1, 2, -1}, {-1, -2, -1}},
+			want: []float64{math.Inf(1), math.Inf(1), 1},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := VarianceInflationFactors(test.rows)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		for j := range test.want {
+			if got[j] != test.want[j] && math.Abs(got[j]-test.want[j]) > 1e-12 {
+				t.Errorf("%s: factor %d = %g, want %g", test.name, j, got[j], test.want[j])
+			}
+		}
+	}
+
+	if _, err := VarianceInflationFactors([][]float64{{1, 2}, {nan, 3}}); !errors.Is(err, ErrTooFewValues) {
+		t.Errorf("one complete row: got error %v, want %v", err, ErrTooFewValues)
+	}
+}
+
+func TestConditionNumber(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name string
+		rows [][]float64
+		want float64
+	}{
+		{"orthonormal columns", [][]float64{{1, 0}, {0, 1}}, 1},
+		// XᵀX is diag(1, 4), so the singular values are 1 and 2.
+		{"scaled column", [][]float64{{1, 0}, {0, 2}}, 2},
+		// XᵀX = [[2, 1], [1, 1]] has eigenvalues (3 ± √5)/2.
+		{"skewed columns", [][]float64{{1, 1}, {1, 0}}, math.Sqrt((3 + math.Sqrt(5)) / (3 - math.Sqrt(5)))},
+		{"rows with missing values are skipped", [][]float64{{1, 0}, {nan, 5}, {0, 2}}, 2},
+		{"collinear columns", [][]float64{{1, 2}, {2, 4}, {3, 6}}, math.Inf(1)},
+	}
+	for _, test := range tests {
+		got, err := ConditionNumber(test.rows)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if got != test.want && math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s: got %.15g, want %.15g", test.name, got, test.want)
+		}
+	}
+
+	if _, err := ConditionNumber([][]float64{{nan, 1}, {2, nan}}); !errors.Is(err, ErrNoData) {
+		t.Errorf("no complete row: got error %v, want %v", err, ErrNoData)
+	}
+}
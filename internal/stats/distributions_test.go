@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// The expected values are scipy.special.kolmogorov(lambda).
+func TestKolmogorovSurvival(t *testing.T) {
+	tests := []struct {
+		lambda float64
+		want   float64
+	}{
+		{0, 1},
+		{0.01, 1},
+		{0.2, 0.999999999999495},
+		{0.5, 0.9639452436648751},
+		{0.8, 0.544142411574198},
+		{1.0, 0.26999967167735456},
+		{1.2, 0.11224966667072496},
+		{2.0, 0.0006709252557796953},
+	}
+	for _, test := range tests {
+		if got := KolmogorovSurvival(test.lambda); math.Abs(got-test.want) > 1e-14 {
+			t.Errorf("lambda=%g: got %.17g, want %.17g", test.lambda, got, test.want)
+		}
+	}
+}
+
+// Both series meet at lambda = 1 without a jump.
+func TestKolmogorovSurvivalIsContinuousAtOne(t *testing.T) {
+	below, above := KolmogorovSurvival(math.Nextafter(1, 0)), KolmogorovSurvival(1)
+	if math.Abs(below-above) > 1e-14 {
+		t.Errorf("got %.17g below 1 and %.17g at 1", below, above)
+	}
+}
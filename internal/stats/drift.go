@@ -0,0 +1,135 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var ErrInvalidBinCount = errors.New("bin count must be positive")
+
+// psiFloor keeps empty bins from sending the index to infinity.
+const psiFloor = 1e-4
+
+// PopulationStabilityIndex compares current against reference on bins holding
+// equal shares of the reference values. Missing values are ignored.
+func PopulationStabilityIndex(reference, current []float64, bins int) (float64, error) {
+	if bins <= 0 {
+		return math.NaN(), ErrInvalidBinCount
+	}
+	reference = RemoveMissingValues(reference)
+	current = RemoveMissingValues(current)
+	if len(reference) == 0 || len(current) == 0 {
+		return math.NaN(), ErrNoData
+	}
+
+	cutPoints := make([]float64, 0, bins-1)
+	for i := 1; i < bins; i++ {
+		cut := Percentile(reference, float64(i)/float64(bins))
+		if len(cutPoints) == 0 || cut > cutPoints[len(cutPoints)-1] {
+			cutPoints = append(cutPoints, cut)
+		}
+	}
+
+	referenceShares := binShares(reference, cutPoints)
+	currentShares := binShares(current, cutPoints)
+
+	index := 0.0
+	for i := range referenceShares {
+		expected := math.Max(referenceShares[i], psiFloor)
+		actual := math.Max(currentShares[i], psiFloor)
+		index += (actual - expected) * math.Log(actual/expected)
+	}
+	return index, nil
+}
+
+func binShares(values []float64, cutPoints []float64) []float64 {
+	shares := make([]float64, len(cutPoints)+1)
+	for _, value := range values {
+		shares[sort.SearchFloat64s(cutPoints, value)]++
+	}
+	for i := range shares {
+		shares[i] /= float64(len(values))
+	}
+	return shares
+}
+
+// TwoSampleKolmogorovSmirnov returns the largest gap between the empirical
+// distribution functions. The p-value is the asymptotic one with Stephens'
+// small-sample correction, so it is below that of scipy's ks_2samp with
+// method="asymp".
+func TwoSampleKolmogorovSmirnov(x, y []float64) (TestResult, error) {
+	x = RemoveMissingValues(x)
+	y = RemoveMissingValues(y)
+	if len(x) == 0 || len(y) == 0 {
+		return TestResult{}, ErrNoData
+	}
+	sort.Float64s(x)
+	sort.Float64s(y)
+
+	statistic := 0.0
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		value := math.Min(x[i], y[j])
+		for i < len(x) && x[i] == value {
+			i++
+		}
+		for j < len(y) && y[j] == value {
+			j++
+		}
+		statistic = math.Max(statistic, math.Abs(float64(i)/float64(len(x))-float64(j)/float64(len(y))))
+	}
+
+	n := float64(len(x))
+	m := float64(len(y))
+	effective := math.Sqrt(n * m / (n + m))
+	return TestResult{
+		Statistic: statistic,
+		PValue:    KolmogorovSurvival((effective + 0.12 + 0.11/effective) * statistic),
+	}, nil
+}
+
+// WassersteinDistance is the first Wasserstein (earth mover's) distance
+// between the empirical distributions, the area between their CDFs.
+func WassersteinDistance(x, y []float64) (float64, error) {
+	x = RemoveMissingValues(x)
+	y = RemoveMissingValues(y)
+	if len(x) == 0 || len(y) == 0 {
+		return math.NaN(), ErrNoData
+	}
+	sort.Float64s(x)
+	sort.Float64s(y)
+
+	all := make([]float64, 0, len(x)+len(y))
+	all = append(all, x...)
+	all = append(all, y...)
+	sort.Float64s(all)
+
+	distance := 0.0
+	i, j := 0, 0
+	for k := 0; k < len(all)-1; k++ {
+		for i < len(x) && x[i] <= all[k] {
+			i++
+		}
+		for j < len(y) && y[j] <= all[k] {
+			j++
+		}
+		gap := float64(i)/float64(len(x)) - float64(j)/float64(len(y))
+		distance += math.Abs(gap) * (all[k+1] - all[k])
+	}
+	return distance, nil
+}
+
+// MissingRate is the share of NaN values.
+func MissingRate(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	missing := 0
+	for _, value := range values {
+		if math.IsNaN(value) {
+			missing++
+		}
+	}
+	return float64(missing) / float64(len(values))
+}
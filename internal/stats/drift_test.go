@@ -0,0 +1,92 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var (
+	driftReference = []float64{0.2, 1.5, 2.3, 2.3, 3.1, 4.8, 5.0, 6.7, math.NaN()}
+	driftCurrent   = []float64{1.1, 2.3, 2.9, 3.8, 4.4, 6.1, 7.5, 8.2, 9.0, 9.9}
+)
+
+// The reference quintiles cut at 2.8, 4.6, 6.4 and 8.2. The current values
+// move a fifth of the rows from the fourth bin to the third, and the empty
+// fourth bin counts as psiFloor.
+func TestPopulationStabilityIndex(t *testing.T) {
+	reference := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	current := []float64{1, 2, 3, 4, 5, 5, 5, 6, 9, 12}
+
+	got, err := PopulationStabilityIndex(reference, current, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := 0.2*math.Log(2) + (psiFloor-0.2)*math.Log(psiFloor/0.2)
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("got %.15g, want %.15g", got, want)
+	}
+
+	if same, _ := PopulationStabilityIndex(reference, reference, 5); same != 0 {
+		t.Errorf("identical samples: got %g, want 0", same)
+	}
+	if _, err := PopulationStabilityIndex(reference, current, 0); !errors.Is(err, ErrInvalidBinCount) {
+		t.Errorf("zero bins: got error %v, want %v", err, ErrInvalidBinCount)
+	}
+	if _, err := PopulationStabilityIndex(reference, []float64{math.NaN()}, 5); !errors.Is(err, ErrNoData) {
+		t.Errorf("no current values: got error %v, want %v", err, ErrNoData)
+	}
+}
+
+// The statistic is that of scipy.stats.ks_2samp. The p-value is
+// scipy.special.kolmogorov of the corrected statistic.
+func TestTwoSampleKolmogorovSmirnov(t *testing.T) {
+	got, err := TwoSampleKolmogorovSmirnov(driftReference, driftCurrent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertTestResult(t, "drifted", got, TestResult{Statistic: 0.4, PValue: 0.376181584779558}, 1e-12)
+
+	same, _ := TwoSampleKolmogorovSmirnov(driftCurrent, driftCurrent)
+	assertTestResult(t, "identical", same, TestResult{Statistic: 0, PValue: 1}, 0)
+
+	if _, err := TwoSampleKolmogorovSmirnov(nil, driftCurrent); !errors.Is(err, ErrNoData) {
+		t.Errorf("no reference values: got error %v, want %v", err, ErrNoData)
+	}
+}
+
+// The expected values are scipy.stats.wasserstein_distance.
+func TestWassersteinDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64
+	}{
+		{"drifted", driftReference, driftCurrent, 2.2825},
+		{"shifted by three", []float64{1, 2, 3}, []float64{4, 5, 6}, 3},
+		{"shifted by five", []float64{0, 1, 3}, []float64{5, 6, 8}, 5},
+		{"identical", driftCurrent, driftCurrent, 0},
+	}
+	for _, test := range tests {
+		got, err := WassersteinDistance(test.x, test.y)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s: got %.15g, want %.15g", test.name, got, test.want)
+		}
+	}
+
+	if _, err := WassersteinDistance(driftReference, []float64{math.NaN()}); !errors.Is(err, ErrNoData) {
+		t.Errorf("no current values: got error %v, want %v", err, ErrNoData)
+	}
+}
+
+func TestMissingRate(t *testing.T) {
+	if got := MissingRate(driftReference); math.Abs(got-1.0/9.0) > 1e-15 {
+		t.Errorf("got %g, want 1/9", got)
+	}
+	if got := MissingRate(nil); !math.IsNaN(got) {
+		t.Errorf("no values: got %g, want NaN", got)
+	}
+}
@@ -0,0 +1,131 @@
+package stats
+
+import "math"
+
+type TestResult struct {
+	Statistic float64
+	PValue    float64
+}
+
+// OneWayANOVA tests whether the groups share a mean. Missing values are
+// ignored and empty groups are skipped.
+func OneWayANOVA(groups [][]float64) (TestResult, error) {
+	filteredGroups := make([][]float64, 0, len(groups))
+	total := 0
+	for _, group := range groups {
+		filtered := RemoveMissingValues(group)
+		if len(filtered) > 0 {
+			filteredGroups = append(filteredGroups, filtered)
+			total += len(filtered)
+		}
+	}
+	if len(filteredGroups) < 2 || total <= len(filteredGroups) {
+		return TestResult{}, ErrTooFewValues
+	}
+
+	all := make([]float64, 0, total)
+	for _, group := range filteredGroups {
+		all = append(all, group...)
+	}
+	grandMean := Mean(all)
+
+	betweenSquares := 0.0
+	withinSquares := 0.0
+	for _, group := range filteredGroups {
+		groupMean := Mean(group)
+		betweenSquares += float64(len(group)) * math.Pow(groupMean-grandMean, 2)
+		for _, value := range group {
+			withinSquares += math.Pow(value-groupMean, 2)
+		}
+	}
+
+	betweenDegrees := float64(len(filteredGroups) - 1)
+	withinDegrees := float64(total - len(filteredGroups))
+	if withinSquares == 0 {
+		return TestResult{Statistic: math.Inf(1), PValue: 0.0}, nil
+	}
+
+	f := (betweenSquares / betweenDegrees) / (withinSquares / withinDegrees)
+	return TestResult{
+		Statistic: f,
+		PValue:    FSurvival(f, betweenDegrees, withinDegrees),
+	}, nil
+}
+
+// ChiSquareIndependence runs Pearson's test on a contingency table of counts.
+// Rows and columns without any counts are dropped before the degrees of
+// freedom are computed.
+func ChiSquareIndependence(table [][]float64) (TestResult, error) {
+	rowTotals, columnTotals, total := contingencyTotals(table)
+	if total == 0 {
+		return TestResult{}, ErrNoData
+	}
+
+	rows := 0
+	for _, rowTotal := range rowTotals {
+		if rowTotal > 0 {
+			rows++
+		}
+	}
+	columns := 0
+	for _, columnTotal := range columnTotals {
+		if columnTotal > 0 {
+			columns++
+		}
+	}
+	if rows < 2 || columns < 2 {
+		return TestResult{}, ErrTooFewValues
+	}
+
+	chiSquare := 0.0
+	for i := range table {
+		for j := range table[i] {
+			expected := rowTotals[i] * columnTotals[j] / total
+			if expected > 0 {
+				chiSquare += math.Pow(table[i][j]-expected, 2) / expected
+			}
+		}
+	}
+
+	return TestResult{
+		Statistic: chiSquare,
+		PValue:    ChiSquareSurvival(chiSquare, float64((rows-1)*(columns-1))),
+	}, nil
+}
+
+// MutualInformation of the two variables of a contingency table, in bits.
+func MutualInformation(table [][]float64) (float64, error) {
+	rowTotals, columnTotals, total := contingencyTotals(table)
+	if total == 0 {
+		return 0.0, ErrNoData
+	}
+
+	information := 0.0
+	for i := range table {
+		for j := range table[i] {
+			if table[i][j] == 0 {
+				continue
+			}
+			joint := table[i][j] / total
+			information += joint * math.Log2(joint*total*total/(rowTotals[i]*columnTotals[j]))
+		}
+	}
+	return information, nil
+}
+
+func contingencyTotals(table [][]float64) ([]float64, []float64, float64) {
+	rowTotals := make([]float64, len(table))
+	columnTotals := make([]float64, 0)
+	total := 0.0
+	for i := range table {
+		for j, count := range table[i] {
+			for len(columnTotals) <= j {
+				columnTotals = append(columnTotals, 0.0)
+			}
+			rowTotals[i] += count
+			columnTotals[j] += count
+			total += count
+		}
+	}
+	return rowTotals, columnTotals, total
+}
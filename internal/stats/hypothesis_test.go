@@ -0,0 +1,135 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// The mussel shell measurements of the scipy.stats.f_oneway documentation,
+// whose result is F=7.121019471642447, p=0.0002812242314534544. The p-value
+// of the three-group case comes from the closed form of the F survival
+// function for an even numerator degree.
+var musselShells = [][]float64{
+	{0.0571, 0.0813, 0.0831, 0.0976, 0.0817, 0.0859, 0.0735, 0.0659, 0.0923, 0.0836},
+	{0.0873, 0.0662, 0.0672, 0.0819, 0.0749, 0.0649, 0.0835, 0.0725},
+	{0.0974, 0.1352, 0.0817, 0.1016, 0.0968, 0.1064, 0.105},
+	{0.1033, 0.0915, 0.0781, 0.0685, 0.0677, 0.0697, 0.0764, 0.0689},
+	{0.0703, 0.1026, 0.0956, 0.0973, 0.1039, 0.1045},
+}
+
+func TestOneWayANOVAMatchesSciPy(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups [][]float64
+		want   TestResult
+	}{
+		{"five groups", musselShells, TestResult{Statistic: 7.121019471642447, PValue: 0.0002812242314534544}},
+		{"three groups", musselShells[:3], TestResult{Statistic: 11.200832489917028, PValue: 0.0004418325189269444}},
+		// Missing values and empty groups are ignored.
+		{"missing values", [][]float64{append([]float64{math.NaN()}, musselShells[0]...), {}, musselShells[1], {math.NaN()}, musselShells[2]},
+			TestResult{Statistic: 11.200832489917028, PValue: 0.0004418325189269444}},
+	}
+	for _, test := range tests {
+		got, err := OneWayANOVA(test.groups)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		assertTestResult(t, test.name, got, test.want, 1e-12)
+	}
+}
+
+func TestOneWayANOVAEdgeCases(t *testing.T) {
+	got, err := OneWayANOVA([][]float64{{1, 1}, {2, 2}})
+	if err != nil || !math.IsInf(got.Statistic, 1) || got.PValue != 0 {
+		t.Errorf("no variance within groups: got %+v, %v", got, err)
+	}
+
+	for name, groups := range map[string][][]float64{
+		"no groups":                nil,
+		"one group":                {{1, 2, 3}},
+		"one group with values":    {{1, 2, 3}, {math.NaN()}},
+		"a single value per group": {{1}, {2}, {3}},
+	} {
+		if _, err := OneWayANOVA(groups); !errors.Is(err, ErrTooFewValues) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrTooFewValues)
+		}
+	}
+}
+
+// The 2×3 table is the example of the scipy.stats.chi2_contingency
+// documentation. The others come from a Python transcription of the statistic,
+// with p-values from the closed forms of the chi-square survival function.
+// The 2×2 case matches chi2_contingency(correction=False), since no continuity
+// correction is applied.
+func TestChiSquareIndependenceMatchesSciPy(t *testing.T) {
+	tests := []struct {
+		name  string
+		table [][]float64
+		want  TestResult
+	}{
+		{"2x3", [][]float64{{10, 10, 20}, {20, 20, 20}}, TestResult{Statistic: 2.7777777777777777, PValue: 0.24935220877729622}},
+		{"3x3", [][]float64{{12, 5, 3}, {4, 15, 6}, {2, 7, 20}}, TestResult{Statistic: 31.54061390760559, PValue: 2.374563149639044e-06}},
+		{"2x2", [][]float64{{30, 10}, {10, 30}}, TestResult{Statistic: 20, PValue: 7.744216431044074e-06}},
+		{"independent", [][]float64{{1, 2}, {2, 4}}, TestResult{Statistic: 0, PValue: 1}},
+		// Empty rows and columns do not count towards the degrees of freedom.
+		{"empty row and column", [][]float64{{10, 0, 10, 20}, {0, 0, 0, 0}, {20, 0, 20, 20}}, TestResult{Statistic: 2.7777777777777777, PValue: 0.24935220877729622}},
+	}
+	for _, test := range tests {
+		got, err := ChiSquareIndependence(test.table)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		assertTestResult(t, test.name, got, test.want, 1e-12)
+	}
+}
+
+func TestChiSquareIndependenceErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		table [][]float64
+		want  error
+	}{
+		{"no table", nil, ErrNoData},
+		{"no counts", [][]float64{{0, 0}, {0, 0}}, ErrNoData},
+		{"one row", [][]float64{{1, 2, 3}}, ErrTooFewValues},
+		{"one column with counts", [][]float64{{1, 0}, {2, 0}}, ErrTooFewValues},
+	}
+	for _, test := range tests {
+		if _, err := ChiSquareIndependence(test.table); !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// The expected values are Σ p(x,y) log2(p(x,y) / (p(x) p(y))) from a Python
+// transcription, which is sklearn.metrics.mutual_info_score divided by ln 2.
+func TestMutualInformation(t *testing.T) {
+	tests := []struct {
+		name  string
+		table [][]float64
+		want  float64
+	}{
+		{"2x3", [][]float64{{10, 10, 20}, {20, 20, 20}}, 0.01997309402197498},
+		{"3x3", [][]float64{{12, 5, 3}, {4, 15, 6}, {2, 7, 20}}, 0.28849095119610557},
+		{"2x2", [][]float64{{30, 10}, {10, 30}}, 0.18872187554086717},
+		{"independent", [][]float64{{1, 2}, {2, 4}}, 0},
+		{"identical", [][]float64{{5, 0}, {0, 5}}, 1},
+	}
+	for _, test := range tests {
+		got, err := MutualInformation(test.table)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s: got %.17g, want %.17g", test.name, got, test.want)
+		}
+	}
+
+	if _, err := MutualInformation([][]float64{{0, 0}}); !errors.Is(err, ErrNoData) {
+		t.Errorf("no counts: got %v, want %v", err, ErrNoData)
+	}
+}
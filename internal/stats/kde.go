@@ -0,0 +1,305 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidBandwidth = errors.New("bandwidth must be positive")
+
+type Kernel int
+
+const (
+	KernelGaussian Kernel = iota
+	KernelEpanechnikov
+	KernelUniform
+	KernelTriangular
+	KernelBiweight
+	KernelCosine
+)
+
+type kernelDefinition struct {
+	name string
+	// density is the kernel at distance u, in units of the bandwidth.
+	density func(u float64) float64
+	// support is the half-width of the kernel's support, infinite for the
+	// Gaussian.
+	support float64
+	// roughness is the integral of the squared kernel and variance its second
+	// moment. Together they give the canonical bandwidth used to carry the
+	// Gaussian rules of thumb over to the other kernels.
+	roughness float64
+	variance  float64
+}
+
+var kernelDefinitions = map[Kernel]kernelDefinition{
+	KernelGaussian: {
+		name: "gaussian",
+		density: func(u float64) float64 {
+			return math.Exp(-u*u/2) / math.Sqrt(2*math.Pi)
+		},
+		support:   math.Inf(1),
+		roughness: 1 / (2 * math.Sqrt(math.Pi)),
+		variance:  1,
+	},
+	KernelEpanechnikov: {
+		name: "epanechnikov",
+		density: func(u float64) float64 {
+			return 0.75 * (1 - u*u)
+		},
+		support:   1,
+		roughness: 3.0 / 5.0,
+		variance:  1.0 / 5.0,
+	},
+	KernelUniform: {
+		name: "uniform",
+		density: func(u float64) float64 {
+			return 0.5
+		},
+		support:   1,
+		roughness: 1.0 / 2.0,
+		variance:  1.0 / 3.0,
+	},
+	KernelTriangular: {
+		name: "triangular",
+		density: func(u float64) float64 {
+			return 1 - math.Abs(u)
+		},
+		support:   1,
+		roughness: 2.0 / 3.0,
+		variance:  1.0 / 6.0,
+	},
+	KernelBiweight: {
+		name: "biweight",
+		density: func(u float64) float64 {
+			return 15.0 / 16.0 * math.Pow(1-u*u, 2)
+		},
+		support:   1,
+		roughness: 5.0 / 7.0,
+		variance:  1.0 / 7.0,
+	},
+	KernelCosine: {
+		name: "cosine",
+		density: func(u float64) float64 {
+			return math.Pi / 4 * math.Cos(math.Pi*u/2)
+		},
+		support:   1,
+		roughness: math.Pi * math.Pi / 16,
+		variance:  1 - 8/(math.Pi*math.Pi),
+	},
+}
+
+func ParseKernel(name string) (Kernel, error) {
+	normalizedName := strings.ToLower(strings.TrimSpace(name))
+	for kernel, definition := range kernelDefinitions {
+		if definition.name == normalizedName {
+			return kernel, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown kernel %q", name)
+}
+
+func (k Kernel) String() string {
+	if definition, ok := kernelDefinitions[k]; ok {
+		return definition.name
+	}
+	return fmt.Sprintf("Kernel(%d)", int(k))
+}
+
+func (k Kernel) evaluate(u float64) float64 {
+	definition := kernelDefinitions[k]
+	if math.Abs(u) > definition.support {
+		return 0.0
+	}
+	return definition.density(u)
+}
+
+func (k Kernel) canonicalBandwidth() float64 {
+	definition := kernelDefinitions[k]
+	return math.Pow(definition.roughness/(definition.variance*definition.variance), 0.2)
+}
+
+type BandwidthMethod int
+
+const (
+	BandwidthSilverman BandwidthMethod = iota
+	BandwidthScott
+	BandwidthLSCV
+)
+
+func ParseBandwidthMethod(name string) (BandwidthMethod, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "silverman":
+		return BandwidthSilverman, nil
+	case "scott":
+		return BandwidthScott, nil
+	case "lscv":
+		return BandwidthLSCV, nil
+	}
+	return 0, fmt.Errorf("unknown bandwidth method %q", name)
+}
+
+func (m BandwidthMethod) String() string {
+	switch m {
+	case BandwidthSilverman:
+		return "silverman"
+	case BandwidthScott:
+		return "scott"
+	case BandwidthLSCV:
+		return "lscv"
+	}
+	return fmt.Sprintf("BandwidthMethod(%d)", int(m))
+}
+
+type KDE struct {
+	Kernel    Kernel
+	Bandwidth float64
+	values    []float64
+}
+
+func NewKDE(values []float64, kernel Kernel, bandwidth float64) (*KDE, error) {
+	values = RemoveMissingValues(values)
+	if len(values) == 0 {
+		return nil, ErrNoData
+	}
+	if !(bandwidth > 0) || math.IsInf(bandwidth, 0) {
+		return nil, ErrInvalidBandwidth
+	}
+
+	return &KDE{
+		Kernel:    kernel,
+		Bandwidth: bandwidth,
+		values:    values,
+	}, nil
+}
+
+func NewKDEWithBandwidthMethod(values []float64, kernel Kernel, method BandwidthMethod) (*KDE, error) {
+	bandwidth, err := SelectBandwidth(values, kernel, method)
+	if err != nil {
+		return nil, err
+	}
+	return NewKDE(values, kernel, bandwidth)
+}
+
+func (k *KDE) Evaluate(x float64) float64 {
+	return kernelDensity(k.values, k.Kernel, k.Bandwidth, x)
+}
+
+func (k *KDE) EvaluateGrid(min float64, max float64, points int) ([]float64, []float64) {
+	xs := make([]float64, points)
+	densities := make([]float64, points)
+	for i := range points {
+		xs[i] = min
+		if points > 1 {
+			xs[i] = min + (max-min)*float64(i)/float64(points-1)
+		}
+		densities[i] = k.Evaluate(xs[i])
+	}
+	return xs, densities
+}
+
+func kernelDensity(values []float64, kernel Kernel, bandwidth float64, x float64) float64 {
+	sum := 0.0
+	for _, value := range values {
+		sum += kernel.evaluate((x - value) / bandwidth)
+	}
+	return sum / (float64(len(values)) * bandwidth)
+}
+
+// SelectBandwidth uses the Gaussian rules of thumb of Silverman and Scott,
+// rescaled to the kernel's canonical bandwidth, or minimizes the least-squares
+// cross-validation score. Cross-validation is quadratic in the number of
+// values.
+func SelectBandwidth(values []float64, kernel Kernel, method BandwidthMethod) (float64, error) {
+	values = RemoveMissingValues(values)
+	if len(values) < 2 {
+		return 0.0, ErrTooFewValues
+	}
+
+	std := Std(values)
+	n := float64(len(values))
+	scale := kernel.canonicalBandwidth() / KernelGaussian.canonicalBandwidth()
+
+	var bandwidth float64
+	switch method {
+	case BandwidthScott:
+		bandwidth = 1.059 * std * math.Pow(n, -0.2) * scale
+	case BandwidthLSCV:
+		return leastSquaresCrossValidation(values, kernel)
+	default:
+		spread := std
+		iqr := (Percentile(values, 0.75) - Percentile(values, 0.25)) / 1.34
+		if iqr > 0 && iqr < spread {
+			spread = iqr
+		}
+		bandwidth = 0.9 * spread * math.Pow(n, -0.2) * scale
+	}
+
+	if !(bandwidth > 0) {
+		return 0.0, ErrInvalidBandwidth
+	}
+	return bandwidth, nil
+}
+
+// leastSquaresCrossValidation minimizes
+//
+//	LSCV(h) = ∫ f̂² - 2/n Σ f̂₋ᵢ(xᵢ)
+//
+// over a logarithmic grid of bandwidths around Silverman's rule. The integral
+// is evaluated numerically so every kernel is handled the same way.
+func leastSquaresCrossValidation(values []float64, kernel Kernel) (float64, error) {
+	reference, err := SelectBandwidth(values, kernel, BandwidthSilverman)
+	if err != nil {
+		return 0.0, err
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	const candidates = 40
+	const gridPoints = 512
+
+	bestBandwidth := reference
+	bestScore := math.Inf(1)
+	for c := range candidates {
+		bandwidth := reference * math.Pow(10, -1+1.5*float64(c)/float64(candidates-1))
+
+		reach := bandwidth * math.Min(kernelDefinitions[kernel].support, 5)
+		min := sorted[0] - reach
+		max := sorted[len(sorted)-1] + reach
+		step := (max - min) / float64(gridPoints-1)
+		integral := 0.0
+		for g := range gridPoints {
+			density := kernelDensity(sorted, kernel, bandwidth, min+step*float64(g))
+			if g == 0 || g == gridPoints-1 {
+				integral += density * density / 2
+			} else {
+				integral += density * density
+			}
+		}
+		integral *= step
+
+		leaveOneOut := 0.0
+		n := float64(len(sorted))
+		for i := range sorted {
+			sum := 0.0
+			for j := range sorted {
+				if i != j {
+					sum += kernel.evaluate((sorted[i] - sorted[j]) / bandwidth)
+				}
+			}
+			leaveOneOut += sum / ((n - 1) * bandwidth)
+		}
+
+		score := integral - 2*leaveOneOut/n
+		if score < bestScore {
+			bestScore = score
+			bestBandwidth = bandwidth
+		}
+	}
+
+	return bestBandwidth, nil
+}
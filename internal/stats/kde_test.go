@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var kdeSample = []float64{1.2, 2.3, 2.9, 3.1, 4.8, 5.0, 7.4, 9.9, 3.3, 4.1}
+
+// The expected bandwidths come from a Python transcription of the rules: the
+// population standard deviation, the interquartile range of
+// numpy.percentile and the Gaussian canonical bandwidth (1/(2√π))^(1/5).
+func TestSelectBandwidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		kernel Kernel
+		method BandwidthMethod
+		want   float64
+	}{
+		// The interquartile range, 1.4925 after dividing by 1.34, is below the
+		// standard deviation 2.4425.
+		{"silverman", kdeSample, KernelGaussian, BandwidthSilverman, 0.8475546418390655},
+		{"scott", kdeSample, KernelGaussian, BandwidthScott, 1.632065500858926},
+		{"silverman epanechnikov", kdeSample, KernelEpanechnikov, BandwidthSilverman, 0.8475546418390655 * 2.2138043588613394},
+		// The interquartile range, 7.46, is above the standard deviation 5.
+		{"silverman wide quartiles", []float64{0, 0, 0, 10, 10, 10}, KernelGaussian, BandwidthSilverman, 0.9 * 5 * math.Pow(6, -0.2)},
+		// A zero interquartile range falls back to the standard deviation.
+		{"silverman zero quartiles", []float64{1, 1, 1, 1, 1, 7}, KernelGaussian, BandwidthSilverman, 0.9 * math.Sqrt(5) * math.Pow(6, -0.2)},
+		{"missing values", append([]float64{math.NaN()}, kdeSample...), KernelGaussian, BandwidthScott, 1.632065500858926},
+	}
+	for _, test := range tests {
+		got, err := SelectBandwidth(test.values, test.kernel, test.method)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s: got %.15g, want %.15g", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSelectBandwidthErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   error
+	}{
+		{"empty", nil, ErrTooFewValues},
+		{"single value", []float64{3}, ErrTooFewValues},
+		{"single value and missing", []float64{3, math.NaN()}, ErrTooFewValues},
+		{"constant", []float64{2, 2, 2}, ErrInvalidBandwidth},
+	}
+	for _, test := range tests {
+		for _, method := range []BandwidthMethod{BandwidthSilverman, BandwidthScott, BandwidthLSCV} {
+			if _, err := SelectBandwidth(test.values, KernelGaussian, method); !errors.Is(err, test.want) {
+				t.Errorf("%s, %s: got %v, want %v", test.name, method, err, test.want)
+			}
+		}
+	}
+}
+
+// Cross-validation searches bandwidths from a tenth of Silverman's rule up to
+// 10^0.5 times it.
+func TestLSCVBandwidthStaysOnItsGrid(t *testing.T) {
+	for _, kernel := range []Kernel{KernelGaussian, KernelEpanechnikov, KernelUniform} {
+		reference, err := SelectBandwidth(kdeSample, kernel, BandwidthSilverman)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kernel, err)
+		}
+		got, err := SelectBandwidth(kdeSample, kernel, BandwidthLSCV)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kernel, err)
+		}
+		if got < reference*0.1*(1-1e-12) || got > reference*math.Sqrt(10)*(1+1e-12) {
+			t.Errorf("%s: got %g outside [%g, %g]", kernel, got, reference*0.1, reference*math.Sqrt(10))
+		}
+	}
+}
+
+func TestKDEEvaluate(t *testing.T) {
+	tests := []struct {
+		kernel Kernel
+		want   float64
+	}{
+		{KernelGaussian, 0.18775534913174918},
+		{KernelEpanechnikov, 0.11425781250000001},
+	}
+	for _, test := range tests {
+		kde, err := NewKDE(kdeSample, test.kernel, 0.8)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.kernel, err)
+		}
+		if got := kde.Evaluate(4); math.Abs(got-test.want) > 1e-15 {
+			t.Errorf("%s: got %.17g, want %.17g", test.kernel, got, test.want)
+		}
+	}
+}
+
+// Every kernel is a density, so the estimate integrates to one. The trapezoid
+// rule loses up to a step at each jump of the uniform kernel.
+func TestKDEIntegratesToOne(t *testing.T) {
+	for kernel := range kernelDefinitions {
+		kde, err := NewKDE(kdeSample, kernel, 0.8)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kernel, err)
+		}
+		xs, densities := kde.EvaluateGrid(-5, 16, 20001)
+		integral := 0.0
+		for i := 1; i < len(xs); i++ {
+			integral += (densities[i-1] + densities[i]) / 2 * (xs[i] - xs[i-1])
+		}
+		if math.Abs(integral-1) > 1e-3 {
+			t.Errorf("%s: integral is %g", kernel, integral)
+		}
+	}
+}
+
+func TestNewKDEErrors(t *testing.T) {
+	if _, err := NewKDE([]float64{math.NaN()}, KernelGaussian, 1); !errors.Is(err, ErrNoData) {
+		t.Errorf("no values: got %v, want %v", err, ErrNoData)
+	}
+	for _, bandwidth := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := NewKDE(kdeSample, KernelGaussian, bandwidth); !errors.Is(err, ErrInvalidBandwidth) {
+			t.Errorf("bandwidth %g: got %v, want %v", bandwidth, err, ErrInvalidBandwidth)
+		}
+	}
+}
+
+func TestParseKernelAndBandwidthMethod(t *testing.T) {
+	for kernel := range kernelDefinitions {
+		if parsed, err := ParseKernel(" " + kernel.String() + " "); err != nil || parsed != kernel {
+			t.Errorf("%s: got %s, %v", kernel, parsed, err)
+		}
+	}
+	for _, method := range []BandwidthMethod{BandwidthSilverman, BandwidthScott, BandwidthLSCV} {
+		if parsed, err := ParseBandwidthMethod(method.String()); err != nil || parsed != method {
+			t.Errorf("%s: got %s, %v", method, parsed, err)
+		}
+	}
+	if _, err := ParseKernel("box"); err == nil {
+		t.Error("expected an error for an unknown kernel")
+	}
+	if _, err := ParseBandwidthMethod("isj"); err == nil {
+		t.Error("expected an error for an unknown bandwidth method")
+	}
+}
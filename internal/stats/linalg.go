@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"errors"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	ErrNotSquare     = errors.New("matrix is not square")
+	ErrNoConvergence = errors.New("eigendecomposition did not converge")
+)
+
+// SymmetricEigen decomposes a symmetric matrix, of which only the upper
+// triangle is read, with gonum's mat.EigenSym. The eigenvalues are returned in
+// descending order, and vectors[k] is the unit eigenvector of values[k].
+func SymmetricEigen(matrix [][]float64) ([]float64, [][]float64, error) {
+	n := len(matrix)
+	for i := range matrix {
+		if len(matrix[i]) != n {
+			return nil, nil, ErrNotSquare
+		}
+	}
+	if n == 0 {
+		return []float64{}, [][]float64{}, nil
+	}
+
+	symmetric := mat.NewSymDense(n, nil)
+	for i := range n {
+		for j := i; j < n; j++ {
+			symmetric.SetSym(i, j, matrix[i][j])
+		}
+	}
+
+	var eigen mat.EigenSym
+	if !eigen.Factorize(symmetric, true) {
+		return nil, nil, ErrNoConvergence
+	}
+	ascending := eigen.Values(nil)
+	var eigenvectors mat.Dense
+	eigen.VectorsTo(&eigenvectors)
+
+	values := make([]float64, n)
+	vectors := make([][]float64, n)
+	for k := range n {
+		column := n - 1 - k
+		values[k] = ascending[column]
+		vectors[k] = mat.Col(nil, column, &eigenvectors)
+	}
+	return values, vectors, nil
+}
@@ -0,0 +1,98 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSymmetricEigenKnownDecomposition(t *testing.T) {
+	values, vectors, err := SymmetricEigen([][]float64{{2, 1}, {1, 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(values[0]-3) > 1e-12 || math.Abs(values[1]-1) > 1e-12 {
+		t.Errorf("got values %v, want [3 1]", values)
+	}
+	// Up to sign, the eigenvectors are (1, 1)/√2 and (1, -1)/√2.
+	if math.Abs(math.Abs(vectors[0][0])-math.Sqrt(0.5)) > 1e-12 || math.Abs(vectors[0][0]-vectors[0][1]) > 1e-12 {
+		t.Errorf("got first vector %v, want ±(1, 1)/√2", vectors[0])
+	}
+	if math.Abs(vectors[1][0]+vectors[1][1]) > 1e-12 {
+		t.Errorf("got second vector %v, want ±(1, -1)/√2", vectors[1])
+	}
+}
+
+// The eigenvectors are orthonormal and Σ values[k]·vₖvₖᵀ rebuilds the matrix,
+// including for a singular matrix and repeated eigenvalues.
+func TestSymmetricEigenReconstructs(t *testing.T) {
+	matrices := map[string][][]float64{
+		"correlations": {
+			{1, 0.8, -0.3, 0.1},
+			{0.8, 1, -0.2, 0.4},
+			{-0.3, -0.2, 1, 0.5},
+			{0.1, 0.4, 0.5, 1},
+		},
+		"singular": {
+			{1, 2, 3},
+			{2, 4, 6},
+			{3, 6, 9},
+		},
+		"repeated": {
+			{2, 0, 0},
+			{0, 5, 0},
+			{0, 0, 2},
+		},
+	}
+
+	for name, matrix := range matrices {
+		values, vectors, err := SymmetricEigen(matrix)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		n := len(matrix)
+		for k := 1; k < n; k++ {
+			if values[k] > values[k-1] {
+				t.Errorf("%s: values %v are not descending", name, values)
+			}
+		}
+
+		for k := range n {
+			for l := range n {
+				dot := 0.0
+				for i := range n {
+					dot += vectors[k][i] * vectors[l][i]
+				}
+				want := 0.0
+				if k == l {
+					want = 1
+				}
+				if math.Abs(dot-want) > 1e-12 {
+					t.Errorf("%s: vectors %d and %d have dot product %g, want %g", name, k, l, dot, want)
+				}
+			}
+		}
+
+		for i := range n {
+			for j := range n {
+				rebuilt := 0.0
+				for k := range n {
+					rebuilt += values[k] * vectors[k][i] * vectors[k][j]
+				}
+				if math.Abs(rebuilt-matrix[i][j]) > 1e-12 {
+					t.Errorf("%s: rebuilt [%d][%d] = %g, want %g", name, i, j, rebuilt, matrix[i][j])
+				}
+			}
+		}
+	}
+}
+
+func TestSymmetricEigenRejectsNonSquare(t *testing.T) {
+	if _, _, err := SymmetricEigen([][]float64{{1, 2}, {3}}); !errors.Is(err, ErrNotSquare) {
+		t.Errorf("got error %v, want %v", err, ErrNotSquare)
+	}
+	values, vectors, err := SymmetricEigen(nil)
+	if err != nil || len(values) != 0 || len(vectors) != 0 {
+		t.Errorf("empty matrix: got %v, %v, %v", values, vectors, err)
+	}
+}
@@ -0,0 +1,255 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var ErrTooManyValues = errors.New("too many values for the test")
+
+const maxShapiroWilkValues = 5000
+
+// ShapiroWilk follows Royston's approximation (Applied Statistics algorithm
+// R94), which covers 3 to 5000 values.
+func ShapiroWilk(values []float64) (TestResult, error) {
+	x := RemoveMissingValues(values)
+	n := len(x)
+	if n < 3 {
+		return TestResult{}, ErrTooFewValues
+	}
+	if n > maxShapiroWilkValues {
+		return TestResult{}, ErrTooManyValues
+	}
+	sort.Float64s(x)
+	if x[0] == x[n-1] {
+		return TestResult{}, ErrTooFewValues
+	}
+
+	coefficients := shapiroWilkCoefficients(n)
+
+	mean := Mean(x)
+	numerator := 0.0
+	denominator := 0.0
+	for i := range x {
+		numerator += coefficients[i] * x[i]
+		denominator += math.Pow(x[i]-mean, 2)
+	}
+	w := math.Min(1.0, numerator*numerator/denominator)
+
+	nf := float64(n)
+	var pValue float64
+	switch {
+	case n == 3:
+		pValue = math.Max(0.0, 6/math.Pi*(math.Asin(math.Sqrt(w))-math.Asin(math.Sqrt(0.75))))
+	case n <= 11:
+		gamma := 0.459*nf - 2.273
+		transformed := -math.Log(gamma - math.Log(1-w))
+		mu := 0.5440 - 0.39978*nf + 0.025054*nf*nf - 0.0006714*nf*nf*nf
+		sigma := math.Exp(1.3822 - 0.77857*nf + 0.062767*nf*nf - 0.0020322*nf*nf*nf)
+		pValue = 1 - NormalCDF((transformed-mu)/sigma)
+	default:
+		logN := math.Log(nf)
+		mu := -1.5861 - 0.31082*logN - 0.083751*logN*logN + 0.0038915*logN*logN*logN
+		sigma := math.Exp(-0.4803 - 0.082676*logN + 0.0030302*logN*logN)
+		pValue = 1 - NormalCDF((math.Log(1-w)-mu)/sigma)
+	}
+
+	return TestResult{Statistic: w, PValue: pValue}, nil
+}
+
+func shapiroWilkCoefficients(n int) []float64 {
+	coefficients := make([]float64, n)
+	if n == 3 {
+		coefficients[0] = -math.Sqrt(0.5)
+		coefficients[2] = math.Sqrt(0.5)
+		return coefficients
+	}
+
+	nf := float64(n)
+	m := make([]float64, n)
+	sumSquares := 0.0
+	for i := range m {
+		m[i] = NormalQuantile((float64(i+1) - 0.375) / (nf + 0.25))
+		sumSquares += m[i] * m[i]
+	}
+
+	u := 1 / math.Sqrt(nf)
+	polynomial := func(c []float64) float64 {
+		result := 0.0
+		for i := len(c) - 1; i >= 0; i-- {
+			result = result*u + c[i]
+		}
+		return result
+	}
+
+	last := m[n-1] / math.Sqrt(sumSquares)
+	last += polynomial([]float64{0, 0.221157, -0.147981, -2.071190, 4.434685, -2.706056})
+	if n <= 5 {
+		phi := (sumSquares - 2*m[n-1]*m[n-1]) / (1 - 2*last*last)
+		for i := range coefficients {
+			coefficients[i] = m[i] / math.Sqrt(phi)
+		}
+		coefficients[0] = -last
+		coefficients[n-1] = last
+		return coefficients
+	}
+
+	secondLast := m[n-2] / math.Sqrt(sumSquares)
+	secondLast += polynomial([]float64{0, 0.042981, -0.293762, -1.752461, 5.682633, -3.582633})
+	phi := (sumSquares - 2*m[n-1]*m[n-1] - 2*m[n-2]*m[n-2]) / (1 - 2*last*last - 2*secondLast*secondLast)
+	for i := range coefficients {
+		coefficients[i] = m[i] / math.Sqrt(phi)
+	}
+	coefficients[0] = -last
+	coefficients[1] = -secondLast
+	coefficients[n-2] = secondLast
+	coefficients[n-1] = last
+	return coefficients
+}
+
+// AndersonDarling tests against a normal distribution with the sample mean
+// and standard deviation. The statistic is corrected for the estimated
+// parameters and the p-value uses the approximation of D'Agostino and Stephens
+// (1986).
+func AndersonDarling(values []float64) (TestResult, error) {
+	z, err := standardizedSorted(values)
+	if err != nil {
+		return TestResult{}, err
+	}
+
+	n := float64(len(z))
+	sum := 0.0
+	for i := range z {
+		lower := NormalCDF(z[i])
+		upper := 1 - NormalCDF(z[len(z)-1-i])
+		sum += float64(2*i+1) * (math.Log(math.Max(lower, 1e-300)) + math.Log(math.Max(upper, 1e-300)))
+	}
+	statistic := -n - sum/n
+	adjusted := statistic * (1 + 0.75/n + 2.25/(n*n))
+
+	var pValue float64
+	switch {
+	case adjusted >= 0.6:
+		pValue = math.Exp(1.2937 - 5.709*adjusted + 0.0186*adjusted*adjusted)
+	case adjusted >= 0.34:
+		pValue = math.Exp(0.9177 - 4.279*adjusted - 1.38*adjusted*adjusted)
+	case adjusted >= 0.2:
+		pValue = 1 - math.Exp(-8.318+42.796*adjusted-59.938*adjusted*adjusted)
+	default:
+		pValue = 1 - math.Exp(-13.436+101.14*adjusted-223.73*adjusted*adjusted)
+	}
+
+	return TestResult{Statistic: statistic, PValue: math.Max(0.0, math.Min(1.0, pValue))}, nil
+}
+
+// KolmogorovSmirnov compares the values with a normal distribution of the
+// sample mean and standard deviation. The p-value comes from the Kolmogorov
+// distribution, which is conservative when the parameters are estimated.
+func KolmogorovSmirnov(values []float64) (TestResult, error) {
+	z, err := standardizedSorted(values)
+	if err != nil {
+		return TestResult{}, err
+	}
+
+	n := float64(len(z))
+	statistic := 0.0
+	for i := range z {
+		cdf := NormalCDF(z[i])
+		statistic = math.Max(statistic, math.Max(float64(i+1)/n-cdf, cdf-float64(i)/n))
+	}
+
+	sqrtN := math.Sqrt(n)
+	return TestResult{
+		Statistic: statistic,
+		PValue:    KolmogorovSurvival((sqrtN + 0.12 + 0.11/sqrtN) * statistic),
+	}, nil
+}
+
+// DAgostinoPearson combines the skewness test of D'Agostino (1970) and the
+// kurtosis test of Anscombe and Glynn (1983) into the omnibus K² statistic,
+// which is chi-square with two degrees of freedom under normality.
+func DAgostinoPearson(values []float64) (TestResult, error) {
+	x := RemoveMissingValues(values)
+	n := float64(len(x))
+	if len(x) < 8 {
+		return TestResult{}, ErrTooFewValues
+	}
+
+	mean := Mean(x)
+	m2, m3, m4 := 0.0, 0.0, 0.0
+	for _, value := range x {
+		deviation := value - mean
+		m2 += deviation * deviation
+		m3 += deviation * deviation * deviation
+		m4 += deviation * deviation * deviation * deviation
+	}
+	m2 /= n
+	m3 /= n
+	m4 /= n
+	if m2 == 0 {
+		return TestResult{}, ErrTooFewValues
+	}
+
+	skewness := m3 / math.Pow(m2, 1.5)
+	kurtosis := m4 / (m2 * m2)
+
+	y := skewness * math.Sqrt((n+1)*(n+3)/(6*(n-2)))
+	beta2 := 3 * (n*n + 27*n - 70) * (n + 1) * (n + 3) / ((n - 2) * (n + 5) * (n + 7) * (n + 9))
+	w2 := -1 + math.Sqrt(2*(beta2-1))
+	delta := 1 / math.Sqrt(0.5*math.Log(w2))
+	alpha := math.Sqrt(2 / (w2 - 1))
+	if y == 0 {
+		y = 1
+	}
+	skewnessZ := delta * math.Log(y/alpha+math.Sqrt((y/alpha)*(y/alpha)+1))
+
+	expected := 3 * (n - 1) / (n + 1)
+	variance := 24 * n * (n - 2) * (n - 3) / ((n + 1) * (n + 1) * (n + 3) * (n + 5))
+	standardized := (kurtosis - expected) / math.Sqrt(variance)
+	sqrtBeta1 := 6 * (n*n - 5*n + 2) / ((n + 7) * (n + 9)) * math.Sqrt(6*(n+3)*(n+5)/(n*(n-2)*(n-3)))
+	a := 6 + 8/sqrtBeta1*(2/sqrtBeta1+math.Sqrt(1+4/(sqrtBeta1*sqrtBeta1)))
+	term1 := 1 - 2/(9*a)
+	denominator := 1 + standardized*math.Sqrt(2/(a-4))
+	term2 := math.Copysign(math.Cbrt((1-2/a)/math.Abs(denominator)), denominator)
+	kurtosisZ := (term1 - term2) / math.Sqrt(2/(9*a))
+
+	statistic := skewnessZ*skewnessZ + kurtosisZ*kurtosisZ
+	return TestResult{
+		Statistic: statistic,
+		PValue:    ChiSquareSurvival(statistic, 2),
+	}, nil
+}
+
+// NormalQQ returns the points of a normal quantile-quantile plot: Blom's
+// plotting positions on the standard normal against the sorted values.
+func NormalQQ(values []float64) ([]float64, []float64) {
+	sample := RemoveMissingValues(values)
+	sort.Float64s(sample)
+
+	n := float64(len(sample))
+	theoretical := make([]float64, len(sample))
+	for i := range sample {
+		theoretical[i] = NormalQuantile((float64(i+1) - 0.375) / (n + 0.25))
+	}
+	return theoretical, sample
+}
+
+func standardizedSorted(values []float64) ([]float64, error) {
+	x := RemoveMissingValues(values)
+	if len(x) < 3 {
+		return nil, ErrTooFewValues
+	}
+
+	mean := Mean(x)
+	std := StdWithDdof(x, 1)
+	if !(std > 0) {
+		return nil, ErrTooFewValues
+	}
+
+	for i := range x {
+		x[i] = (x[i] - mean) / std
+	}
+	sort.Float64s(x)
+	return x, nil
+}
@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var normalitySamples = map[string][]float64{
+	"symmetric": {2.1, 3.4, 1.9, 5.6, 4.4, 3.3, 2.8, 4.9, 3.7, 4.1, 2.5, 3.9, 6.2, 3.0, 4.6},
+	"skewed":    {0.1, 0.2, 0.2, 0.3, 0.5, 0.7, 1.0, 1.4, 2.2, 3.9, 7.5, 12.0},
+	"seven":     {1.2, 2.3, 2.9, 3.1, 4.8, 5.0, 9.7},
+	"three":     {1, 2, 4},
+}
+
+func assertTestResult(t *testing.T, name string, got TestResult, want TestResult, tolerance float64) {
+	t.Helper()
+	if math.Abs(got.Statistic-want.Statistic) > tolerance || math.Abs(got.PValue-want.PValue) > tolerance {
+		t.Errorf("%s: got %.12g (p=%.12g), want %.12g (p=%.12g)", name, got.Statistic, got.PValue, want.Statistic, want.PValue)
+	}
+}
+
+// The expected values follow scipy.stats.shapiro, whose swilk computes the
+// normal scores with a quantile function accurate to about 1e-7.
+func TestShapiroWilkMatchesSciPy(t *testing.T) {
+	tests := map[string]TestResult{
+		"symmetric": {0.9783720001281452, 0.957171405532093},
+		"skewed":    {0.6979868496946192, 0.0008058949051163999},
+		"seven":     {0.8716591345140959, 0.19192255399310676},
+		"three":     {0.9642857142857144, 0.6368868450289701},
+	}
+	for name, want := range tests {
+		got, err := ShapiroWilk(normalitySamples[name])
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		assertTestResult(t, name, got, want, 1e-6)
+	}
+}
+
+// The expected values are scipy.stats.normaltest.
+func TestDAgostinoPearsonMatchesSciPy(t *testing.T) {
+	tests := map[string]TestResult{
+		"symmetric": {0.49506884867936324, 0.780723344462721},
+		"skewed":    {13.102093088356277, 0.0014286197020516914},
+	}
+	for name, want := range tests {
+		got, err := DAgostinoPearson(normalitySamples[name])
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		assertTestResult(t, name, got, want, 1e-10)
+	}
+
+	if _, err := DAgostinoPearson(normalitySamples["seven"]); !errors.Is(err, ErrTooFewValues) {
+		t.Errorf("seven values: got error %v, want %v", err, ErrTooFewValues)
+	}
+}
+
+// scipy.stats.anderson gives no p-value; the expected values are those of
+// statsmodels.stats.diagnostic.normal_ad, which uses the same approximation.
+func TestAndersonDarlingMatchesStatsmodels(t *testing.T) {
+	tests := map[string]TestResult{
+		"symmetric": {0.11978906754430163, 0.9850165130979566},
+		"skewed":    {1.528550673452239, 0.0003146532297489423},
+		"seven":     {0.4581570310567651, 0.17764679229457986},
+		"three":     {0.22964543670862136, 0.4867387715951307},
+	}
+	for name, want := range tests {
+		got, err := AndersonDarling(normalitySamples[name])
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		assertTestResult(t, name, got, want, 1e-10)
+	}
+}
+
+func TestNormalityTestsRejectConstantValues(t *testing.T) {
+	constant := []float64{3, 3, 3, 3, 3, 3, 3, 3, math.NaN()}
+	tests := map[string]func([]float64) (TestResult, error){
+		"shapiro-wilk":       ShapiroWilk,
+		"anderson-darling":   AndersonDarling,
+		"kolmogorov-smirnov": KolmogorovSmirnov,
+		"dagostino-pearson":  DAgostinoPearson,
+	}
+	for name, test := range tests {
+		if _, err := test(constant); !errors.Is(err, ErrTooFewValues) {
+			t.Errorf("%s: got error %v, want %v", name, err, ErrTooFewValues)
+		}
+	}
+}
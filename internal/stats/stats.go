@@ -1,8 +1,17 @@
 package stats
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"sort"
+	"strings"
+)
+
+var (
+	ErrNoData         = errors.New("no non-missing values")
+	ErrLengthMismatch = errors.New("value slices have different lengths")
+	ErrTooFewValues   = errors.New("not enough values for the requested degrees of freedom")
 )
 
 func Sum(values []float64) float64 {
@@ -18,6 +27,14 @@ func Sum(values []float64) float64 {
 }
 
 func Mean(values []float64) float64 {
+	mean, err := MeanE(values)
+	if err != nil {
+		return math.NaN()
+	}
+	return mean
+}
+
+func MeanE(values []float64) (float64, error) {
 	count := 0
 	for _, value := range values {
 		if math.IsNaN(value) {
@@ -26,12 +43,29 @@ func Mean(values []float64) float64 {
 
 		count++
 	}
+	if count == 0 {
+		return 0.0, ErrNoData
+	}
 
-	return Sum(values) / float64(count)
+	return Sum(values) / float64(count), nil
 }
 
-func Std(values []float64) float64 {
-	mean := Mean(values)
+func Variance(values []float64, ddof int) float64 {
+	variance, err := VarianceE(values, ddof)
+	if err != nil {
+		return math.NaN()
+	}
+	return variance
+}
+
+// VarianceE divides the sum of squared deviations by n-ddof: ddof 0 gives the
+// population variance and ddof 1 the unbiased sample variance.
+func VarianceE(values []float64, ddof int) (float64, error) {
+	mean, err := MeanE(values)
+	if err != nil {
+		return 0.0, err
+	}
+
 	sum := 0.0
 	count := 0
 	for _, value := range values {
@@ -42,14 +76,47 @@ func Std(values []float64) float64 {
 		sum += math.Pow(value-mean, 2)
 		count++
 	}
-	if count == 0 {
-		return 0.0
+	if count-ddof <= 0 {
+		return 0.0, ErrTooFewValues
 	}
-	return math.Sqrt(sum / float64(count))
+	return sum / float64(count-ddof), nil
+}
+
+func Std(values []float64) float64 {
+	return StdWithDdof(values, 0)
+}
+
+func StdE(values []float64) (float64, error) {
+	return StdWithDdofE(values, 0)
+}
+
+func StdWithDdof(values []float64, ddof int) float64 {
+	std, err := StdWithDdofE(values, ddof)
+	if err != nil {
+		return math.NaN()
+	}
+	return std
+}
+
+func StdWithDdofE(values []float64, ddof int) (float64, error) {
+	variance, err := VarianceE(values, ddof)
+	if err != nil {
+		return 0.0, err
+	}
+	return math.Sqrt(variance), nil
 }
 
 func Min(values []float64) float64 {
-	min := values[0]
+	min, err := MinE(values)
+	if err != nil {
+		return math.NaN()
+	}
+	return min
+}
+
+func MinE(values []float64) (float64, error) {
+	min := math.Inf(1)
+	count := 0
 	for _, value := range values {
 		if math.IsNaN(value) {
 			continue
@@ -58,12 +125,25 @@ func Min(values []float64) float64 {
 		if value < min {
 			min = value
 		}
+		count++
 	}
-	return min
+	if count == 0 {
+		return 0.0, ErrNoData
+	}
+	return min, nil
 }
 
 func Max(values []float64) float64 {
-	max := values[0]
+	max, err := MaxE(values)
+	if err != nil {
+		return math.NaN()
+	}
+	return max
+}
+
+func MaxE(values []float64) (float64, error) {
+	max := math.Inf(-1)
+	count := 0
 	for _, value := range values {
 		if math.IsNaN(value) {
 			continue
@@ -72,8 +152,55 @@ func Max(values []float64) float64 {
 		if value > max {
 			max = value
 		}
+		count++
 	}
-	return max
+	if count == 0 {
+		return 0.0, ErrNoData
+	}
+	return max, nil
+}
+
+type PercentileMethod int
+
+// The methods follow the definitions used by NumPy and pandas. Linear is the
+// default in both, so it is the zero value here as well.
+const (
+	PercentileLinear PercentileMethod = iota
+	PercentileLower
+	PercentileHigher
+	PercentileNearest
+	PercentileMidpoint
+	PercentileHazen
+	PercentileWeibull
+	PercentileMedianUnbiased
+)
+
+var percentileMethodNames = map[PercentileMethod]string{
+	PercentileLinear:         "linear",
+	PercentileLower:          "lower",
+	PercentileHigher:         "higher",
+	PercentileNearest:        "nearest",
+	PercentileMidpoint:       "midpoint",
+	PercentileHazen:          "hazen",
+	PercentileWeibull:        "weibull",
+	PercentileMedianUnbiased: "median_unbiased",
+}
+
+func ParsePercentileMethod(name string) (PercentileMethod, error) {
+	normalizedName := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+	for method, methodName := range percentileMethodNames {
+		if methodName == normalizedName {
+			return method, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown percentile method %q", name)
+}
+
+func (m PercentileMethod) String() string {
+	if name, ok := percentileMethodNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("PercentileMethod(%d)", int(m))
 }
 
 func Q25(values []float64) float64 {
@@ -89,40 +216,73 @@ func Q75(values []float64) float64 {
 }
 
 func Percentile(values []float64, p float64) float64 {
-	values = RemoveMissingValues(values)
+	return PercentileWithMethod(values, p, PercentileLinear)
+}
 
-	if p <= 0.0 {
-		return values[0]
-	}
-	if p >= 1.0 {
-		return values[len(values)-1]
+func PercentileWithMethod(values []float64, p float64, method PercentileMethod) float64 {
+	percentile, err := PercentileE(values, p, method)
+	if err != nil {
+		return math.NaN()
 	}
+	return percentile
+}
 
-	if len(values) == 0 {
-		return 0.0
-	}
+func PercentileE(values []float64, p float64, method PercentileMethod) (float64, error) {
+	values = RemoveMissingValues(values)
 
-	if len(values) == 1 {
-		return values[0]
+	if len(values) == 0 {
+		return 0.0, ErrNoData
 	}
 
 	sort.Float64s(values)
 
-	exactIndex := p * float64(len(values)+1)
-	lowerIndex := int(exactIndex)
-	upperIndex := lowerIndex + 1
-	if upperIndex-1 >= len(values) {
-		return values[len(values)-1]
+	if len(values) == 1 || p <= 0.0 {
+		return values[0], nil
 	}
-	if lowerIndex <= 0 {
-		return values[0]
+	if p >= 1.0 {
+		return values[len(values)-1], nil
 	}
-	if float64(lowerIndex) == exactIndex {
-		return values[lowerIndex-1]
+
+	lastIndex := float64(len(values) - 1)
+	switch method {
+	case PercentileLower:
+		return values[int(math.Floor(lastIndex*p))], nil
+	case PercentileHigher:
+		return values[int(math.Ceil(lastIndex*p))], nil
+	case PercentileNearest:
+		return values[int(math.RoundToEven(lastIndex*p))], nil
+	case PercentileMidpoint:
+		lower := values[int(math.Floor(lastIndex*p))]
+		upper := values[int(math.Ceil(lastIndex*p))]
+		return (lower + upper) / 2, nil
 	}
 
-	weight := exactIndex - float64(lowerIndex)
-	return values[lowerIndex-1] + (values[upperIndex-1]-values[lowerIndex-1])*weight
+	index := virtualIndex(len(values), p, method)
+	if index <= 0 {
+		return values[0], nil
+	}
+	if index >= lastIndex {
+		return values[len(values)-1], nil
+	}
+
+	lowerIndex := int(math.Floor(index))
+	weight := index - float64(lowerIndex)
+	return values[lowerIndex] + (values[lowerIndex+1]-values[lowerIndex])*weight, nil
+}
+
+// virtualIndex is the 0-based position of the percentile among n sorted
+// values, before clamping. The discontinuous methods round the linear one.
+func virtualIndex(n int, p float64, method PercentileMethod) float64 {
+	count := float64(n)
+	switch method {
+	case PercentileHazen:
+		return count*p - 0.5
+	case PercentileWeibull:
+		return (count+1)*p - 1
+	case PercentileMedianUnbiased:
+		return (count+1.0/3.0)*p - 2.0/3.0
+	}
+	return (count - 1) * p
 }
 
 func FillMissingValuesWithMean(values []float64) []float64 {
@@ -145,7 +305,66 @@ func RemoveMissingValues(values []float64) []float64 {
 	return newValues
 }
 
+func Covariance(xValues []float64, yValues []float64, ddof int) float64 {
+	covariance, err := CovarianceE(xValues, yValues, ddof)
+	if err != nil {
+		return math.NaN()
+	}
+	return covariance
+}
+
+// CovarianceE only uses the pairs where both values are present.
+func CovarianceE(xValues []float64, yValues []float64, ddof int) (float64, error) {
+	filteredXValues, filteredYValues, err := completePairs(xValues, yValues)
+	if err != nil {
+		return 0.0, err
+	}
+
+	xMean := Mean(filteredXValues)
+	yMean := Mean(filteredYValues)
+
+	covariance := 0.0
+	for i := range filteredXValues {
+		covariance += (filteredXValues[i] - xMean) * (filteredYValues[i] - yMean)
+	}
+	if len(filteredXValues)-ddof <= 0 {
+		return 0.0, ErrTooFewValues
+	}
+	return covariance / float64(len(filteredXValues)-ddof), nil
+}
+
 func CalculateCorrelation(xValues []float64, yValues []float64) float64 {
+	correlation, err := CalculateCorrelationE(xValues, yValues)
+	if err != nil {
+		return math.NaN()
+	}
+	return correlation
+}
+
+// The degrees-of-freedom correction cancels out in the correlation, so it does
+// not take a ddof argument. It needs at least two complete pairs.
+func CalculateCorrelationE(xValues []float64, yValues []float64) (float64, error) {
+	filteredXValues, filteredYValues, err := completePairs(xValues, yValues)
+	if err != nil {
+		return 0.0, err
+	}
+	if len(filteredXValues) < 2 {
+		return 0.0, ErrTooFewValues
+	}
+
+	covariance, err := CovarianceE(filteredXValues, filteredYValues, 0)
+	if err != nil {
+		return 0.0, err
+	}
+
+	return covariance / (Std(filteredXValues) * Std(filteredYValues)), nil
+}
+
+func completePairs(xValues []float64, yValues []float64) ([]float64, []float64, error) {
+	if len(xValues) != len(yValues) {
+		return nil, nil, ErrLengthMismatch
+	}
+
 	filteredXValues := make([]float64, 0, len(xValues))
 	filteredYValues := make([]float64, 0, len(yValues))
 	for i := range xValues {
@@ -156,15 +375,9 @@ func CalculateCorrelation(xValues []float64, yValues []float64) float64 {
 		filteredXValues = append(filteredXValues, xValues[i])
 		filteredYValues = append(filteredYValues, yValues[i])
 	}
-
-	xMean := Mean(filteredXValues)
-	yMean := Mean(filteredYValues)
-
-	covariance := 0.0
-	for i := range filteredXValues {
-		covariance += (filteredXValues[i] - xMean) * (filteredYValues[i] - yMean)
+	if len(filteredXValues) == 0 {
+		return nil, nil, ErrNoData
 	}
-	covariance = covariance / float64(len(filteredXValues))
 
-	return covariance / (Std(filteredXValues) * Std(filteredYValues))
+	return filteredXValues, filteredYValues, nil
 }
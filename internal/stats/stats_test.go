@@ -0,0 +1,164 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// The expected values are numpy.percentile(values, 100*p, method=...) on
+// [10, 7, 4, 3, 2, 1].
+func TestPercentileEMatchesNumPy(t *testing.T) {
+	values := []float64{10, 7, 4, 3, 2, 1}
+	ps := []float64{0, 0.1, 0.25, 0.5, 0.7, 0.9, 1}
+
+	tests := []struct {
+		method PercentileMethod
+		want   []float64
+	}{
+		{PercentileLinear, []float64{1, 1.5, 2.25, 3.5, 5.5, 8.5, 10}},
+		{PercentileLower, []float64{1, 1, 2, 3, 4, 7, 10}},
+		{PercentileHigher, []float64{1, 2, 3, 4, 7, 10, 10}},
+		{PercentileNearest, []float64{1, 1, 2, 3, 7, 7, 10}},
+		{PercentileMidpoint, []float64{1, 1.5, 2.5, 3.5, 5.5, 8.5, 10}},
+		{PercentileHazen, []float64{1, 1.1, 2, 3.5, 6.1, 9.7, 10}},
+		{PercentileWeibull, []float64{1, 1, 1.75, 3.5, 6.7, 10, 10}},
+		{PercentileMedianUnbiased, []float64{1, 1, 23.0 / 12.0, 3.5, 6.3, 10, 10}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method.String(), func(t *testing.T) {
+			for i, p := range ps {
+				got, err := PercentileE(values, p, test.method)
+				if err != nil {
+					t.Fatalf("p=%g: unexpected error: %v", p, err)
+				}
+				if math.Abs(got-test.want[i]) > 1e-12 {
+					t.Errorf("p=%g: got %g, want %g", p, got, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPercentileESingleValue(t *testing.T) {
+	for method := range percentileMethodNames {
+		for _, p := range []float64{0, 0.25, 0.5, 1} {
+			got, err := PercentileE([]float64{5}, p, method)
+			if err != nil {
+				t.Fatalf("%s p=%g: unexpected error: %v", method, p, err)
+			}
+			if got != 5 {
+				t.Errorf("%s p=%g: got %g, want 5", method, p, got)
+			}
+		}
+	}
+}
+
+// Missing values are skipped, as numpy.nanpercentile does.
+func TestPercentileEIgnoresMissingValues(t *testing.T) {
+	values := []float64{math.NaN(), 4, 1, math.NaN(), 3, 2}
+	got, err := PercentileE(values, 0.5, PercentileLinear)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 2.5 {
+		t.Errorf("got %g, want 2.5", got)
+	}
+
+	_, err = PercentileE([]float64{math.NaN()}, 0.5, PercentileLinear)
+	if !errors.Is(err, ErrNoData) {
+		t.Errorf("got error %v, want %v", err, ErrNoData)
+	}
+}
+
+func TestParsePercentileMethod(t *testing.T) {
+	for method, name := range percentileMethodNames {
+		parsed, err := ParsePercentileMethod(name)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", name, err)
+		}
+		if parsed != method {
+			t.Errorf("%q: got %s, want %s", name, parsed, method)
+		}
+	}
+	if _, err := ParsePercentileMethod("cubic"); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
+
+// Empty and all-missing input has no data, one value leaves no degrees of
+// freedom for ddof 1 or a correlation, and pairs must have equal lengths.
+func TestCheckedFunctionsReturnSentinelErrors(t *testing.T) {
+	missing := []float64{math.NaN(), math.NaN()}
+	single := []float64{3, math.NaN()}
+	tests := []struct {
+		name string
+		call func() (float64, error)
+		want error
+	}{
+		{"MeanE empty", func() (float64, error) { return MeanE(nil) }, ErrNoData},
+		{"MeanE missing", func() (float64, error) { return MeanE(missing) }, ErrNoData},
+		{"VarianceE empty", func() (float64, error) { return VarianceE(nil, 0) }, ErrNoData},
+		{"VarianceE single", func() (float64, error) { return VarianceE(single, 1) }, ErrTooFewValues},
+		{"StdE missing", func() (float64, error) { return StdE(missing) }, ErrNoData},
+		{"StdWithDdofE single", func() (float64, error) { return StdWithDdofE(single, 1) }, ErrTooFewValues},
+		{"MinE empty", func() (float64, error) { return MinE(nil) }, ErrNoData},
+		{"MinE missing", func() (float64, error) { return MinE(missing) }, ErrNoData},
+		{"MaxE empty", func() (float64, error) { return MaxE(nil) }, ErrNoData},
+		{"MaxE missing", func() (float64, error) { return MaxE(missing) }, ErrNoData},
+		{"PercentileE empty", func() (float64, error) { return PercentileE(nil, 0.5, PercentileLinear) }, ErrNoData},
+		{"CovarianceE empty", func() (float64, error) { return CovarianceE(nil, nil, 0) }, ErrNoData},
+		{"CovarianceE no complete pair", func() (float64, error) { return CovarianceE([]float64{1, math.NaN()}, []float64{math.NaN(), 2}, 0) }, ErrNoData},
+		{"CovarianceE single", func() (float64, error) { return CovarianceE(single, []float64{1, 2}, 1) }, ErrTooFewValues},
+		{"CovarianceE mismatch", func() (float64, error) { return CovarianceE([]float64{1, 2}, []float64{1}, 0) }, ErrLengthMismatch},
+		{"CalculateCorrelationE empty", func() (float64, error) { return CalculateCorrelationE(nil, nil) }, ErrNoData},
+		{"CalculateCorrelationE single", func() (float64, error) { return CalculateCorrelationE(single, []float64{1, 2}) }, ErrTooFewValues},
+		{"CalculateCorrelationE mismatch", func() (float64, error) { return CalculateCorrelationE([]float64{1}, []float64{1, 2}) }, ErrLengthMismatch},
+	}
+	for _, test := range tests {
+		if got, err := test.call(); !errors.Is(err, test.want) {
+			t.Errorf("%s: got %g, %v, want %v", test.name, got, err, test.want)
+		}
+	}
+}
+
+// A single value is a valid sample for everything without a ddof correction.
+func TestCheckedFunctionsAcceptASingleValue(t *testing.T) {
+	single := []float64{math.NaN(), 3}
+	tests := []struct {
+		name string
+		call func() (float64, error)
+		want float64
+	}{
+		{"MeanE", func() (float64, error) { return MeanE(single) }, 3},
+		{"VarianceE", func() (float64, error) { return VarianceE(single, 0) }, 0},
+		{"StdE", func() (float64, error) { return StdE(single) }, 0},
+		{"MinE", func() (float64, error) { return MinE(single) }, 3},
+		{"MaxE", func() (float64, error) { return MaxE(single) }, 3},
+		{"CovarianceE", func() (float64, error) { return CovarianceE(single, []float64{1, 2}, 0) }, 0},
+	}
+	for _, test := range tests {
+		if got, err := test.call(); err != nil || got != test.want {
+			t.Errorf("%s: got %g, %v, want %g", test.name, got, err, test.want)
+		}
+	}
+}
+
+// The unchecked functions return NaN where the checked ones fail.
+func TestUncheckedFunctionsReturnNaN(t *testing.T) {
+	for name, got := range map[string]float64{
+		"Mean":                 Mean(nil),
+		"Variance":             Variance([]float64{1}, 1),
+		"Std":                  Std([]float64{math.NaN()}),
+		"Min":                  Min(nil),
+		"Max":                  Max([]float64{math.NaN()}),
+		"Percentile":           Percentile(nil, 0.5),
+		"Covariance":           Covariance([]float64{1}, []float64{1, 2}, 0),
+		"CalculateCorrelation": CalculateCorrelation([]float64{1}, []float64{2}),
+	} {
+		if !math.IsNaN(got) {
+			t.Errorf("%s: got %g, want NaN", name, got)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var ErrNegativeWeight = errors.New("weights must not be negative")
+
+type weightedValue struct {
+	value  float64
+	weight float64
+}
+
+// Pairs with a missing value or weight and pairs with zero weight do not
+// contribute to any weighted statistic.
+func filterWeighted(values []float64, weights []float64) ([]weightedValue, error) {
+	if len(values) != len(weights) {
+		return nil, ErrLengthMismatch
+	}
+
+	filtered := make([]weightedValue, 0, len(values))
+	for i := range values {
+		if math.IsNaN(values[i]) || math.IsNaN(weights[i]) {
+			continue
+		}
+		if weights[i] < 0 {
+			return nil, ErrNegativeWeight
+		}
+		if weights[i] == 0 {
+			continue
+		}
+
+		filtered = append(filtered, weightedValue{value: values[i], weight: weights[i]})
+	}
+	if len(filtered) == 0 {
+		return nil, ErrNoData
+	}
+
+	return filtered, nil
+}
+
+func WeightedMean(values []float64, weights []float64) float64 {
+	mean, err := WeightedMeanE(values, weights)
+	if err != nil {
+		return math.NaN()
+	}
+	return mean
+}
+
+func WeightedMeanE(values []float64, weights []float64) (float64, error) {
+	filtered, err := filterWeighted(values, weights)
+	if err != nil {
+		return 0.0, err
+	}
+
+	return weightedMean(filtered), nil
+}
+
+func weightedMean(filtered []weightedValue) float64 {
+	sum := 0.0
+	weightSum := 0.0
+	for _, wv := range filtered {
+		sum += wv.weight * wv.value
+		weightSum += wv.weight
+	}
+	return sum / weightSum
+}
+
+func WeightedVariance(values []float64, weights []float64, ddof int) float64 {
+	variance, err := WeightedVarianceE(values, weights, ddof)
+	if err != nil {
+		return math.NaN()
+	}
+	return variance
+}
+
+// The weights are treated as reliability weights, so the result does not depend
+// on their scale: the correction divides by V1 - ddof*V2/V1, where V1 and V2
+// are the sums of the weights and of their squares. With equal weights this is
+// n - ddof in units of the weight.
+func WeightedVarianceE(values []float64, weights []float64, ddof int) (float64, error) {
+	filtered, err := filterWeighted(values, weights)
+	if err != nil {
+		return 0.0, err
+	}
+
+	mean := weightedMean(filtered)
+	sum := 0.0
+	weightSum := 0.0
+	squaredWeightSum := 0.0
+	for _, wv := range filtered {
+		sum += wv.weight * math.Pow(wv.value-mean, 2)
+		weightSum += wv.weight
+		squaredWeightSum += wv.weight * wv.weight
+	}
+	return correctedWeightedSum(sum, weightSum, squaredWeightSum, ddof)
+}
+
+// correctedWeightedSum divides a weighted sum of squared deviations by the
+// reliability-weight correction of WeightedVarianceE.
+func correctedWeightedSum(sum float64, weightSum float64, squaredWeightSum float64, ddof int) (float64, error) {
+	denominator := weightSum - float64(ddof)*squaredWeightSum/weightSum
+	if denominator <= 1e-12*weightSum {
+		return 0.0, ErrTooFewValues
+	}
+	return sum / denominator, nil
+}
+
+func WeightedStd(values []float64, weights []float64, ddof int) float64 {
+	std, err := WeightedStdE(values, weights, ddof)
+	if err != nil {
+		return math.NaN()
+	}
+	return std
+}
+
+func WeightedStdE(values []float64, weights []float64, ddof int) (float64, error) {
+	variance, err := WeightedVarianceE(values, weights, ddof)
+	if err != nil {
+		return 0.0, err
+	}
+	return math.Sqrt(variance), nil
+}
+
+func WeightedCovariance(xValues []float64, yValues []float64, weights []float64, ddof int) float64 {
+	covariance, err := WeightedCovarianceE(xValues, yValues, weights, ddof)
+	if err != nil {
+		return math.NaN()
+	}
+	return covariance
+}
+
+func WeightedCovarianceE(xValues []float64, yValues []float64, weights []float64, ddof int) (float64, error) {
+	if len(xValues) != len(yValues) || len(xValues) != len(weights) {
+		return 0.0, ErrLengthMismatch
+	}
+
+	filteredXValues := make([]float64, 0, len(xValues))
+	filteredYValues := make([]float64, 0, len(yValues))
+	filteredWeights := make([]float64, 0, len(weights))
+	for i := range xValues {
+		if math.IsNaN(xValues[i]) || math.IsNaN(yValues[i]) {
+			continue
+		}
+
+		filteredXValues = append(filteredXValues, xValues[i])
+		filteredYValues = append(filteredYValues, yValues[i])
+		filteredWeights = append(filteredWeights, weights[i])
+	}
+
+	xMean, err := WeightedMeanE(filteredXValues, filteredWeights)
+	if err != nil {
+		return 0.0, err
+	}
+	yMean, err := WeightedMeanE(filteredYValues, filteredWeights)
+	if err != nil {
+		return 0.0, err
+	}
+
+	covariance := 0.0
+	weightSum := 0.0
+	squaredWeightSum := 0.0
+	for i := range filteredXValues {
+		if math.IsNaN(filteredWeights[i]) {
+			continue
+		}
+
+		covariance += filteredWeights[i] * (filteredXValues[i] - xMean) * (filteredYValues[i] - yMean)
+		weightSum += filteredWeights[i]
+		squaredWeightSum += filteredWeights[i] * filteredWeights[i]
+	}
+	return correctedWeightedSum(covariance, weightSum, squaredWeightSum, ddof)
+}
+
+func WeightedPercentile(values []float64, weights []float64, p float64, method PercentileMethod) float64 {
+	percentile, err := WeightedPercentileE(values, weights, p, method)
+	if err != nil {
+		return math.NaN()
+	}
+	return percentile
+}
+
+// WeightedPercentileE places the k-th sorted value at the fraction of the total
+// weight that precedes it, (S_k - w_k) / (S_N - w_N). The method's virtual
+// index among the N values, as a fraction of N-1, is located between those
+// positions and then interpolated or rounded as the method does. With equal
+// weights it matches PercentileE.
+func WeightedPercentileE(values []float64, weights []float64, p float64, method PercentileMethod) (float64, error) {
+	filtered, err := filterWeighted(values, weights)
+	if err != nil {
+		return 0.0, err
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].value < filtered[j].value
+	})
+
+	last := len(filtered) - 1
+	if last == 0 || p <= 0.0 {
+		return filtered[0].value, nil
+	}
+	if p >= 1.0 {
+		return filtered[last].value, nil
+	}
+
+	fraction := virtualIndex(len(filtered), p, method) / float64(last)
+	if fraction <= 0.0 {
+		return filtered[0].value, nil
+	}
+	if fraction >= 1.0 {
+		return filtered[last].value, nil
+	}
+
+	totalWeight := 0.0
+	for _, wv := range filtered {
+		totalWeight += wv.weight
+	}
+	scale := totalWeight - filtered[last].weight
+
+	precedingWeight := 0.0
+	for k := 0; k < last; k++ {
+		position := precedingWeight / scale
+		nextPosition := (precedingWeight + filtered[k].weight) / scale
+		if fraction <= nextPosition {
+			weight := (fraction - position) / (nextPosition - position)
+			return interpolate(filtered[k].value, filtered[k+1].value, k, weight, method), nil
+		}
+		precedingWeight += filtered[k].weight
+	}
+
+	return filtered[last].value, nil
+}
+
+// interpolate applies the method between the sorted values at index and
+// index+1. Rounding errors in the cumulative weights can leave the weight just
+// off 0, 1 or a nearest-method tie at 0.5, so it is snapped to those.
+func interpolate(lower float64, upper float64, index int, weight float64, method PercentileMethod) float64 {
+	const snap = 1e-9
+	if weight < snap {
+		return lower
+	}
+	if weight > 1-snap {
+		return upper
+	}
+
+	switch method {
+	case PercentileLower:
+		return lower
+	case PercentileHigher:
+		return upper
+	case PercentileNearest:
+		if math.Abs(weight-0.5) < snap {
+			weight = 0.5
+		}
+		if weight < 0.5 || (weight == 0.5 && index%2 == 0) {
+			return lower
+		}
+		return upper
+	case PercentileMidpoint:
+		return (lower + upper) / 2
+	}
+	return lower + (upper-lower)*weight
+}
+
+func WeightedCorrelation(xValues []float64, yValues []float64, weights []float64) float64 {
+	correlation, err := WeightedCorrelationE(xValues, yValues, weights)
+	if err != nil {
+		return math.NaN()
+	}
+	return correlation
+}
+
+// As with CalculateCorrelationE, the degrees-of-freedom correction cancels out
+// and at least two complete pairs with a positive weight are needed.
+func WeightedCorrelationE(xValues []float64, yValues []float64, weights []float64) (float64, error) {
+	covariance, err := WeightedCovarianceE(xValues, yValues, weights, 0)
+	if err != nil {
+		return 0.0, err
+	}
+
+	filteredWeights := make([]float64, len(weights))
+	pairs := 0
+	for i := range weights {
+		filteredWeights[i] = weights[i]
+		if math.IsNaN(xValues[i]) || math.IsNaN(yValues[i]) {
+			filteredWeights[i] = 0.0
+		}
+		if filteredWeights[i] > 0 {
+			pairs++
+		}
+	}
+	if pairs < 2 {
+		return 0.0, ErrTooFewValues
+	}
+
+	return covariance / (WeightedStd(xValues, filteredWeights, 0) * WeightedStd(yValues, filteredWeights, 0)), nil
+}
@@ -0,0 +1,129 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWeightedPercentileEEqualWeightsMatchPercentileE(t *testing.T) {
+	values := []float64{10, 7, 4, 3, 2, 1, 8}
+	for _, weight := range []float64{1, 0.1, 3} {
+		weights := make([]float64, len(values))
+		for i := range weights {
+			weights[i] = weight
+		}
+
+		for method := range percentileMethodNames {
+			for _, p := range []float64{0, 0.1, 0.25, 0.5, 0.7, 0.75, 0.9, 1} {
+				want, _ := PercentileE(values, p, method)
+				got, err := WeightedPercentileE(values, weights, p, method)
+				if err != nil {
+					t.Fatalf("%s p=%g: unexpected error: %v", method, p, err)
+				}
+				if math.Abs(got-want) > 1e-12 {
+					t.Errorf("%s p=%g weight=%g: got %g, want %g", method, p, weight, got, want)
+				}
+			}
+		}
+	}
+}
+
+// With weights [2, 1, 1] the sorted values sit at positions 0, 2/3 and 1, so
+// the median falls three quarters of the way from 1 to 2.
+func TestWeightedPercentileEUnequalWeights(t *testing.T) {
+	values := []float64{3, 1, 2}
+	weights := []float64{1, 2, 1}
+
+	tests := []struct {
+		method PercentileMethod
+		want   float64
+	}{
+		{PercentileLinear, 1.75},
+		{PercentileLower, 1},
+		{PercentileHigher, 2},
+		{PercentileNearest, 2},
+		{PercentileMidpoint, 1.5},
+	}
+	for _, test := range tests {
+		got, err := WeightedPercentileE(values, weights, 0.5, test.method)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.method, err)
+		}
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s: got %g, want %g", test.method, got, test.want)
+		}
+	}
+}
+
+func TestWeightedVarianceEReliabilityWeights(t *testing.T) {
+	values := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	for _, ddof := range []int{0, 1} {
+		want, _ := VarianceE(values, ddof)
+		// Normalized weights sum to one, which a frequency correction could
+		// not divide by once ddof is 1.
+		for _, weight := range []float64{1, 3, 1.0 / 8.0} {
+			weights := make([]float64, len(values))
+			for i := range weights {
+				weights[i] = weight
+			}
+			got, err := WeightedVarianceE(values, weights, ddof)
+			if err != nil {
+				t.Fatalf("ddof=%d weight=%g: unexpected error: %v", ddof, weight, err)
+			}
+			if math.Abs(got-want) > 1e-12 {
+				t.Errorf("ddof=%d weight=%g: got %g, want %g", ddof, weight, got, want)
+			}
+		}
+	}
+
+	// Scaling unequal weights leaves the estimate unchanged.
+	weights := []float64{1, 2, 3, 1, 2, 3, 1, 2}
+	scaled := make([]float64, len(weights))
+	for i := range weights {
+		scaled[i] = weights[i] / 15
+	}
+	want, _ := WeightedVarianceE(values, weights, 1)
+	got, _ := WeightedVarianceE(values, scaled, 1)
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("scaled weights: got %g, want %g", got, want)
+	}
+
+	if _, err := WeightedVarianceE([]float64{3}, []float64{0.5}, 1); err != ErrTooFewValues {
+		t.Errorf("single value: got error %v, want %v", err, ErrTooFewValues)
+	}
+}
+
+// Pairs with a zero or missing weight count as missing.
+func TestWeightedCheckedFunctionsReturnSentinelErrors(t *testing.T) {
+	values := []float64{1, 2, 3}
+	tests := []struct {
+		name string
+		call func() (float64, error)
+		want error
+	}{
+		{"WeightedMeanE empty", func() (float64, error) { return WeightedMeanE(nil, nil) }, ErrNoData},
+		{"WeightedMeanE zero weights", func() (float64, error) { return WeightedMeanE(values, []float64{0, 0, math.NaN()}) }, ErrNoData},
+		{"WeightedMeanE mismatch", func() (float64, error) { return WeightedMeanE(values, []float64{1, 1}) }, ErrLengthMismatch},
+		{"WeightedMeanE negative", func() (float64, error) { return WeightedMeanE(values, []float64{1, -1, 1}) }, ErrNegativeWeight},
+		{"WeightedVarianceE single", func() (float64, error) { return WeightedVarianceE(values, []float64{0, 2, 0}, 1) }, ErrTooFewValues},
+		{"WeightedStdE empty", func() (float64, error) { return WeightedStdE(nil, nil, 0) }, ErrNoData},
+		{"WeightedPercentileE empty", func() (float64, error) { return WeightedPercentileE(nil, nil, 0.5, PercentileLinear) }, ErrNoData},
+		{"WeightedPercentileE mismatch", func() (float64, error) { return WeightedPercentileE(values, nil, 0.5, PercentileLinear) }, ErrLengthMismatch},
+		{"WeightedCovarianceE empty", func() (float64, error) { return WeightedCovarianceE(nil, nil, nil, 0) }, ErrNoData},
+		{"WeightedCovarianceE single", func() (float64, error) {
+			return WeightedCovarianceE(values, []float64{3, math.NaN(), 1}, []float64{1, 1, 0}, 1)
+		}, ErrTooFewValues},
+		{"WeightedCovarianceE mismatch", func() (float64, error) { return WeightedCovarianceE(values, values, []float64{1}, 0) }, ErrLengthMismatch},
+		{"WeightedCorrelationE empty", func() (float64, error) { return WeightedCorrelationE(nil, nil, nil) }, ErrNoData},
+		{"WeightedCorrelationE single", func() (float64, error) {
+			return WeightedCorrelationE(values, []float64{3, math.NaN(), 1}, []float64{1, 1, 0})
+		}, ErrTooFewValues},
+		{"WeightedCorrelationE mismatch", func() (float64, error) { return WeightedCorrelationE(values, []float64{1, 2}, values) }, ErrLengthMismatch},
+	}
+	for _, test := range tests {
+		if got, err := test.call(); !errors.Is(err, test.want) {
+			t.Errorf("%s: got %g, %v, want %v", test.name, got, err, test.want)
+		}
+	}
+}